@@ -1,5 +1,10 @@
 @echo off
 echo Building OpenCode Config Wizard for Windows...
+go vet ./...
+if %errorlevel% neq 0 (
+    echo Vet failed!
+    exit /b 1
+)
 go build -o opencode-config-wizard.exe .
 if %errorlevel% neq 0 (
     echo Build failed!
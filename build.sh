@@ -1,5 +1,10 @@
 #!/bin/bash
 echo "Building OpenCode Config Wizard for Linux..."
+go vet ./...
+if [ $? -ne 0 ]; then
+    echo "Vet failed!"
+    exit 1
+fi
 go build -o opencode-config-wizard .
 if [ $? -ne 0 ]; then
     echo "Build failed!"
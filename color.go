@@ -0,0 +1,49 @@
+package main
+
+import (
+	"os"
+
+	"golang.org/x/term"
+)
+
+// noColor disables colored output; set by the --no-color flag.
+var noColor bool
+
+const (
+	ansiReset  = "\033[0m"
+	ansiRed    = "\033[31m"
+	ansiGreen  = "\033[32m"
+	ansiYellow = "\033[33m"
+	ansiDim    = "\033[2m"
+)
+
+// colorEnabled reports whether output should be colored: only when stdout is
+// a terminal and neither --no-color nor NO_COLOR (https://no-color.org) is set.
+func colorEnabled() bool {
+	if noColor || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	// A character device isn't necessarily a terminal (/dev/null is one),
+	// so ask the terminal driver.
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+func colorize(code, s string) string {
+	if !colorEnabled() {
+		return s
+	}
+	return code + s + ansiReset
+}
+
+func green(s string) string  { return colorize(ansiGreen, s) }
+func red(s string) string    { return colorize(ansiRed, s) }
+func dim(s string) string    { return colorize(ansiDim, s) }
+func yellow(s string) string { return colorize(ansiYellow, s) }
+
+// statusText renders an MCP server's enabled state, colored when supported.
+func statusText(enabled bool) string {
+	if enabled {
+		return green("enabled")
+	}
+	return red("disabled")
+}
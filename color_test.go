@@ -0,0 +1,25 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+// /dev/null is a character device but not a terminal, so redirecting output
+// there must not produce escape codes.
+func TestColorDisabledForDevNull(t *testing.T) {
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devNull.Close()
+
+	stdout := os.Stdout
+	os.Stdout = devNull
+	enabled := colorEnabled()
+	os.Stdout = stdout
+
+	if enabled {
+		t.Error("color enabled with stdout redirected to", os.DevNull)
+	}
+}
@@ -1,12 +1,89 @@
 package main
 
 import (
-	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	ocfg "github.com/liamwilliams93/opencode-config-wizard/pkg/config"
 )
 
+// compactOutput makes saveConfig write minified JSON instead of indented JSON.
+var compactOutput bool
+
+// indentOption is the --indent flag: a number of spaces or "tab".
+var indentOption = "2"
+
+// outputIndent returns the indentation saved configs use, or "" for minified
+// output.
+func outputIndent() (string, error) {
+	if compactOutput {
+		return "", nil
+	}
+	if indentOption == "tab" {
+		return "\t", nil
+	}
+	n, err := strconv.Atoi(indentOption)
+	if err != nil || n < 1 || n > 8 {
+		return "", fmt.Errorf("%w --indent '%s': use a number of spaces from 1 to 8, or 'tab'", ocfg.ErrInvalid, indentOption)
+	}
+	return strings.Repeat(" ", n), nil
+}
+
+// useLocalConfig makes getConfigPath return the project config in the
+// current directory instead of the global one; set by the --local flag.
+var useLocalConfig bool
+
+// localConfigCandidates are the project config locations opencode reads, in
+// the order they are looked for.
+var localConfigCandidates = []string{
+	"opencode.json",
+	filepath.Join(".opencode", "opencode.json"),
+}
+
+// useGlobalConfig makes getConfigPath return the global config even when the
+// current directory has a project config; set by the --global flag.
+var useGlobalConfig bool
+
+// projectConfigDetected records that useLocalConfig was set because the
+// current directory has a project config, rather than by --local.
+var projectConfigDetected bool
+
+// detectProjectConfig switches to the project config in the current
+// directory, as opencode would read it, unless --local or --global chose a
+// config already. It says which config is in use on stderr, so output meant
+// for piping is unaffected.
+func detectProjectConfig() {
+	if useLocalConfig || useGlobalConfig {
+		return
+	}
+	path := findLocalConfig()
+	if path == "" {
+		return
+	}
+	if globalPath, err := getGlobalConfigPath(); err == nil && samePath(path, globalPath) {
+		return
+	}
+
+	useLocalConfig = true
+	projectConfigDetected = true
+	if !jsonErrors {
+		fmt.Fprintf(os.Stderr, "Using project config %s (pass --global for the global config)\n", path)
+	}
+}
+
 func getConfigPath() (string, error) {
+	if useLocalConfig {
+		return getLocalConfigPath()
+	}
+	return getGlobalConfigPath()
+}
+
+func getGlobalConfigPath() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return "", err
@@ -14,40 +91,287 @@ func getConfigPath() (string, error) {
 	return filepath.Join(homeDir, ".config", "opencode", "opencode.json"), nil
 }
 
+// getLocalConfigPath returns the first existing project config in the current
+// directory, or ./opencode.json if there is none yet.
+func getLocalConfigPath() (string, error) {
+	if path := findLocalConfig(); path != "" {
+		return path, nil
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cwd, localConfigCandidates[0]), nil
+}
+
+// findLocalConfig returns the first existing project config in the current
+// directory, or "" if there is none.
+func findLocalConfig() string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	for _, candidate := range localConfigCandidates {
+		path := filepath.Join(cwd, candidate)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// loadMergedConfig loads the global and the project config and layers the
+// project config over the global one, as opencode does. It also returns the
+// two paths; either file may be missing.
+func loadMergedConfig() (*Config, string, string, error) {
+	globalPath, err := getGlobalConfigPath()
+	if err != nil {
+		return nil, "", "", err
+	}
+	localPath, err := getLocalConfigPath()
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	global, err := loadConfig(globalPath)
+	if err != nil {
+		return nil, "", "", err
+	}
+	local, err := loadConfig(localPath)
+	if err != nil {
+		return nil, "", "", err
+	}
+	return ocfg.Merge(global, local), globalPath, localPath, nil
+}
+
+// describeConfigFile returns path, noting if the file doesn't exist.
+func describeConfigFile(path string) string {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return path + " (not found)"
+	}
+	return path
+}
+
+// loadConfig reads the config at path, printing any problems that were
+// tolerated while parsing it. A missing file yields an empty config.
 func loadConfig(path string) (*Config, error) {
-	config := &Config{
-		Schema:   "https://opencode.ai/config.json",
-		Provider: make(map[string]Provider),
+	config, warnings, err := ocfg.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	printConfigWarnings(warnings)
+	return config, nil
+}
+
+// loadConfigData parses config JSON, filling in any missing sections.
+func loadConfigData(data []byte) (*Config, error) {
+	config, warnings, err := ocfg.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	printConfigWarnings(warnings)
+	return config, nil
+}
+
+func printConfigWarnings(warnings []string) {
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+}
+
+// currentCommand is the name of the command being run. It is recorded in the
+// names of automatic backups so undo can say what it reverted.
+var currentCommand string
+
+// configBackedUp records whether the active config has already been backed up
+// during the current command, so a command that saves several times leaves a
+// single backup of the state before it ran.
+var configBackedUp bool
+
+// lastBackupPath is the backup backupConfig most recently wrote, so a command
+// that leaves the backup to saveConfig can still say where it went.
+var lastBackupPath string
+
+// maxBackups is how many backups are kept per config; older ones are removed.
+const maxBackups = 20
+
+// backupTimeFormat is the timestamp layout used in backup file names.
+const backupTimeFormat = "20060102-150405"
+
+// getBackupDir returns the directory backups of the config at path are kept
+// in. Project backups go under .opencode so they don't clutter the project,
+// and each profile keeps its own, so undo never crosses a profile switch.
+func getBackupDir(path string) string {
+	if name := profileOf(path); name != "" {
+		if dir, err := getProfilesDir(); err == nil {
+			return filepath.Join(dir, name, "backups")
+		}
+	}
+
+	dir := filepath.Dir(path)
+	if useLocalConfig && filepath.Base(dir) != ".opencode" {
+		dir = filepath.Join(dir, ".opencode")
 	}
+	return filepath.Join(dir, "backups")
+}
 
+// backupConfig copies the config at path into a timestamped file in the
+// backups directory and returns the backup's path. The current command name,
+// if any, is included in the file name.
+func backupConfig(path string) (string, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return config, nil
+		return "", err
+	}
+
+	backupDir := getBackupDir(path)
+	if err := os.MkdirAll(backupDir, 0700); err != nil {
+		return "", err
+	}
+
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	name := fmt.Sprintf("%s-%s", base, time.Now().Format(backupTimeFormat))
+	if currentCommand != "" {
+		name += "-" + currentCommand
+	}
+	backupPath := filepath.Join(backupDir, name+".json")
+	for i := 2; ; i++ {
+		if _, err := os.Stat(backupPath); os.IsNotExist(err) {
+			break
 		}
-		return nil, err
+		backupPath = filepath.Join(backupDir, fmt.Sprintf("%s.%d.json", name, i))
+	}
+
+	if err := os.WriteFile(backupPath, data, 0600); err != nil {
+		return "", err
+	}
+
+	if activePath, err := getConfigPath(); err == nil && activePath == path {
+		configBackedUp = true
 	}
+	lastBackupPath = backupPath
+	pruneBackups(path)
+
+	return backupPath, nil
+}
+
+// listBackups returns the backups of the config at path, newest first.
+func listBackups(path string) ([]string, error) {
+	backupDir := getBackupDir(path)
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
 
-	if err := json.Unmarshal(data, config); err != nil {
+	entries, err := os.ReadDir(backupDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
 		return nil, err
 	}
 
-	if config.Provider == nil {
-		config.Provider = make(map[string]Provider)
+	type backup struct {
+		path    string
+		modTime time.Time
+	}
+	var backups []backup
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), base+"-") || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backup{filepath.Join(backupDir, entry.Name()), info.ModTime()})
 	}
 
-	if config.MCP == nil {
-		config.MCP = make(map[string]MCPServer)
+	sort.SliceStable(backups, func(i, j int) bool {
+		if backups[i].modTime.Equal(backups[j].modTime) {
+			return backups[i].path > backups[j].path
+		}
+		return backups[i].modTime.After(backups[j].modTime)
+	})
+
+	paths := make([]string, len(backups))
+	for i, b := range backups {
+		paths[i] = b.path
 	}
+	return paths, nil
+}
 
-	return config, nil
+// pruneBackups removes all but the newest maxBackups backups of the config at
+// path. Failures are ignored; a leftover backup does no harm.
+func pruneBackups(path string) {
+	backups, err := listBackups(path)
+	if err != nil || len(backups) <= maxBackups {
+		return
+	}
+	for _, old := range backups[maxBackups:] {
+		os.Remove(old)
+	}
 }
 
 func saveConfig(config *Config, path string) error {
-	data, err := json.MarshalIndent(config, "", "  ")
+	var previous *Config
+	if activePath, err := getConfigPath(); err == nil && activePath == path {
+		previous = readConfigQuietly(path)
+
+		// Keep the state from before this command so it can be undone.
+		if _, err := os.Stat(path); err == nil && !configBackedUp {
+			if _, err := backupConfig(path); err != nil {
+				return fmt.Errorf("backing up config: %w", err)
+			}
+		}
+	}
+
+	indent, err := outputIndent()
+	if err != nil {
+		return err
+	}
+	if err := ocfg.Save(config, path, indent); err != nil {
+		return err
+	}
+
+	if previous != nil {
+		logConfigChange(path, previous, config)
+	}
+	recordSave(path, previous, config)
+
+	if empty := config.EmptyProviders(); len(empty) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: provider(s) with no models: %s\n", strings.Join(empty, ", "))
+	}
+	return nil
+}
+
+// readConfigQuietly loads the config at path without printing the warnings
+// loadConfig would, returning an empty config if it is missing or unreadable.
+func readConfigQuietly(path string) *Config {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ocfg.New()
+	}
+	config, _, err := ocfg.Parse(data)
+	if err != nil {
+		return ocfg.New()
+	}
+	return config
+}
+
+func printConfigPath(args []string) error {
+	configPath, err := getConfigPath()
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(path, data, 0644)
+	scope := "global"
+	if useLocalConfig {
+		scope = "project"
+	}
+
+	status := ""
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		status = ", not created yet"
+	}
+
+	fmt.Printf("%s (%s%s)\n", configPath, scope, status)
+	return nil
 }
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectProjectConfig(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		file      string
+		global    bool
+		wantLocal bool
+	}{
+		{"no project config", "", false, false},
+		{"opencode.json", "opencode.json", false, true},
+		{".opencode/opencode.json", filepath.Join(".opencode", "opencode.json"), false, true},
+		{"--global", "opencode.json", true, false},
+	} {
+		home := t.TempDir()
+		t.Setenv("HOME", home)
+		t.Setenv("USERPROFILE", home)
+		dir := t.TempDir()
+		t.Chdir(dir)
+		if tc.file != "" {
+			path := filepath.Join(dir, tc.file)
+			if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte("{}\n"), 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		useLocalConfig, useGlobalConfig, projectConfigDetected = false, tc.global, false
+		detectProjectConfig()
+		path, err := getConfigPath()
+		useLocalConfig, useGlobalConfig, projectConfigDetected = false, false, false
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := filepath.Join(home, ".config", "opencode", "opencode.json")
+		if tc.wantLocal {
+			want = filepath.Join(dir, tc.file)
+		}
+		if !samePath(path, want) {
+			t.Errorf("%s: config path %s, want %s", tc.name, path, want)
+		}
+	}
+}
@@ -0,0 +1,437 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	ocfg "github.com/liamwilliams93/opencode-config-wizard/pkg/config"
+)
+
+// defaultTestTimeout is used for connectivity checks when the server has no
+// timeout of its own configured.
+const defaultTestTimeout = 10 * time.Second
+
+// initialRetryBackoff is the wait before the first retry; it doubles after
+// each further failure.
+const initialRetryBackoff = 500 * time.Millisecond
+
+// testOptions controls how connectivity checks retry. Timeout, if set, caps
+// the total time spent across all attempts.
+type testOptions struct {
+	Retries int
+	Timeout time.Duration
+}
+
+// proxyOverride, if set, is the proxy used for all HTTP requests instead of
+// the one from HTTP_PROXY/HTTPS_PROXY; set by the --proxy flag.
+var proxyOverride string
+
+// newHTTPClient returns a client with the given timeout that goes through
+// proxyOverride, or the proxy from the environment if there is none.
+func newHTTPClient(timeout time.Duration) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	if proxyOverride != "" {
+		proxy, err := url.Parse(proxyOverride)
+		if err != nil || proxy.Host == "" {
+			return nil, fmt.Errorf("%w proxy URL '%s'", ocfg.ErrInvalid, proxyOverride)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	return &http.Client{Timeout: timeout, Transport: transport}, nil
+}
+
+// addTestFlags registers the retry and proxy flags shared by the
+// connectivity test commands.
+func addTestFlags(fs *flag.FlagSet) *testOptions {
+	opts := &testOptions{}
+	fs.IntVar(&opts.Retries, "retries", 0, "retry failed checks up to this many times")
+	fs.DurationVar(&opts.Timeout, "timeout", 0, "overall time limit across retries (e.g. 30s)")
+	fs.StringVar(&proxyOverride, "proxy", "", "proxy URL to use instead of HTTP_PROXY/HTTPS_PROXY")
+	return opts
+}
+
+// checkTestFlags rejects a --timeout that was given but isn't positive; left
+// unset, there is no overall limit.
+func checkTestFlags(fs *flag.FlagSet, opts *testOptions) error {
+	timeoutSet := false
+	fs.Visit(func(f *flag.Flag) { timeoutSet = timeoutSet || f.Name == "timeout" })
+	if timeoutSet && opts.Timeout <= 0 {
+		return fmt.Errorf("%w --timeout '%s': must be greater than zero", ocfg.ErrInvalid, opts.Timeout)
+	}
+	return nil
+}
+
+// run calls attempt until it succeeds or the retries are used up, waiting
+// with exponential backoff in between. Each attempt gets attemptTimeout, cut
+// short if the overall timeout is nearer. It returns the number of tries.
+func (o testOptions) run(attemptTimeout time.Duration, attempt func(timeout time.Duration) error) (int, error) {
+	var deadline time.Time
+	if o.Timeout > 0 {
+		deadline = time.Now().Add(o.Timeout)
+	}
+
+	backoff := initialRetryBackoff
+	for tries := 1; ; tries++ {
+		timeout := attemptTimeout
+		if !deadline.IsZero() {
+			if remaining := time.Until(deadline); remaining < timeout {
+				timeout = remaining
+			}
+		}
+
+		err := attempt(timeout)
+		if err == nil || tries > o.Retries {
+			return tries, err
+		}
+		if !deadline.IsZero() && time.Until(deadline) <= backoff {
+			return tries, fmt.Errorf("%w (--timeout reached)", err)
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// retryError notes in err how many attempts were made, if more than one.
+func retryError(err error, tries int) error {
+	if tries <= 1 {
+		return err
+	}
+	return fmt.Errorf("failed after %d tries: %w", tries, err)
+}
+
+// triesSuffix describes how many attempts a check took, if more than one.
+func triesSuffix(tries int) string {
+	if tries <= 1 {
+		return ""
+	}
+	return fmt.Sprintf(" after %d tries", tries)
+}
+
+var placeholderPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandPlaceholders replaces ${VAR} references in value with the matching
+// environment variables. Placeholders are only ever expanded at test time;
+// the config always keeps the ${VAR} form. Unset variables are returned.
+func expandPlaceholders(value string) (string, []string) {
+	var missing []string
+	expanded := placeholderPattern.ReplaceAllStringFunc(value, func(match string) string {
+		name := placeholderPattern.FindStringSubmatch(match)[1]
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return v
+	})
+	return expanded, missing
+}
+
+// expandPlaceholderMap expands every value in m, collecting unset variables.
+func expandPlaceholderMap(m map[string]string) (map[string]string, []string) {
+	expanded := make(map[string]string, len(m))
+	var missing []string
+	for k, v := range m {
+		value, unset := expandPlaceholders(v)
+		expanded[k] = value
+		missing = append(missing, unset...)
+	}
+	sort.Strings(missing)
+	return expanded, missing
+}
+
+func testMCPServer(args []string) error {
+	fs := flag.NewFlagSet("test-mcp", flag.ContinueOnError)
+	opts := addTestFlags(fs)
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := checkTestFlags(fs, opts); err != nil {
+		return err
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("usage: test-mcp [--retries <n>] [--timeout <duration>] [name]")
+	}
+
+	configPath, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	var serverName string
+	if len(args) == 1 {
+		serverName = args[0]
+		if _, exists := config.MCP[serverName]; !exists {
+			return fmt.Errorf("MCP server '%s' %w", serverName, ocfg.ErrNotFound)
+		}
+	} else {
+		if len(config.MCP) == 0 {
+			fmt.Println("No MCP servers configured")
+			return nil
+		}
+
+		fmt.Println("\n=== Test MCP Server ===")
+
+		serverName = promptMCPServerName(config)
+		if serverName == "" {
+			return errCancelled
+		}
+		if _, exists := config.MCP[serverName]; !exists {
+			return fmt.Errorf("MCP server '%s' %w", serverName, ocfg.ErrNotFound)
+		}
+	}
+
+	server := config.MCP[serverName]
+	fmt.Printf("Testing MCP server: %s (%s)\n", serverName, server.Type)
+
+	if server.Type == "local" {
+		return testLocalMCPServer(server)
+	}
+	return testRemoteMCPServer(server, *opts)
+}
+
+func testLocalMCPServer(server MCPServer) error {
+	if len(server.Command) == 0 {
+		return fmt.Errorf("no command configured")
+	}
+
+	_, missing := expandPlaceholderMap(server.Environment)
+	if len(missing) > 0 {
+		return fmt.Errorf("environment references unset variable(s): %s", strings.Join(missing, ", "))
+	}
+
+	path, err := exec.LookPath(server.Command[0])
+	if err != nil {
+		return fmt.Errorf("command '%s' not found on PATH", server.Command[0])
+	}
+
+	fmt.Printf("OK: command found at %s\n", path)
+	return nil
+}
+
+func testRemoteMCPServer(server MCPServer, opts testOptions) error {
+	if server.URL == "" {
+		return fmt.Errorf("no URL configured")
+	}
+
+	headers, missing := expandPlaceholderMap(server.Headers)
+	if len(missing) > 0 {
+		return fmt.Errorf("headers reference unset variable(s): %s", strings.Join(missing, ", "))
+	}
+
+	timeout := defaultTestTimeout
+	if server.Timeout != nil {
+		timeout = time.Duration(*server.Timeout) * time.Millisecond
+	}
+
+	var status string
+	var elapsed time.Duration
+	tries, err := opts.run(timeout, func(timeout time.Duration) error {
+		var err error
+		status, elapsed, err = sendMCPInitialize(server.URL, headers, timeout)
+		return err
+	})
+	if err != nil {
+		return retryError(err, tries)
+	}
+
+	fmt.Printf("OK: server responded with %s in %s%s\n", status, elapsed, triesSuffix(tries))
+	return nil
+}
+
+// sendMCPInitialize posts an MCP initialize request to serverURL and returns the
+// response status and how long it took.
+func sendMCPInitialize(serverURL string, headers map[string]string, timeout time.Duration) (string, time.Duration, error) {
+	// An MCP initialize request is the cheapest call every server must answer.
+	body := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"opencode-config-wizard","version":"1.0.0"}}}`
+	req, err := http.NewRequest(http.MethodPost, serverURL, bytes.NewBufferString(body))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	client, err := newHTTPClient(timeout)
+	if err != nil {
+		return "", 0, err
+	}
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("request failed: %w", err)
+	}
+	resp.Body.Close()
+	elapsed := time.Since(start).Round(time.Millisecond)
+
+	if resp.StatusCode >= 400 {
+		return "", 0, fmt.Errorf("server responded with %s after %s", resp.Status, elapsed)
+	}
+	return resp.Status, elapsed, nil
+}
+
+// providerTestResult is the outcome of checking one provider's /models
+// endpoint.
+type providerTestResult struct {
+	Key     string
+	Models  int
+	Tries   int
+	Elapsed time.Duration
+	Err     error
+}
+
+// testProviderConnection checks that the provider answers /models. Elapsed
+// is the time taken by the last attempt.
+func testProviderConnection(key string, provider Provider, opts testOptions) providerTestResult {
+	result := providerTestResult{Key: key}
+	result.Tries, result.Err = opts.run(defaultTestTimeout, func(timeout time.Duration) error {
+		start := time.Now()
+		ids, err := fetchProviderModels(provider, timeout)
+		result.Models = len(ids)
+		result.Elapsed = time.Since(start).Round(time.Millisecond)
+		return err
+	})
+	return result
+}
+
+func testProvider(args []string) error {
+	fs := flag.NewFlagSet("test-provider", flag.ContinueOnError)
+	opts := addTestFlags(fs)
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := checkTestFlags(fs, opts); err != nil {
+		return err
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("usage: test-provider [--retries <n>] [--timeout <duration>] [provider]")
+	}
+
+	configPath, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	var selector string
+	if len(args) == 1 {
+		selector = args[0]
+	} else if len(config.Provider) == 0 {
+		fmt.Println("No providers configured")
+		return nil
+	}
+
+	providerKey, err := selectProvider(config, selector, "\n=== Test Provider ===")
+	if err != nil {
+		return err
+	}
+	provider := config.Provider[providerKey]
+
+	fmt.Printf("Testing provider: %s (%s)\n", providerKey, provider.Options["baseURL"])
+	result := testProviderConnection(providerKey, provider, *opts)
+	if result.Err != nil {
+		return retryError(result.Err, result.Tries)
+	}
+
+	fmt.Printf("OK: %d model(s) available, responded in %s%s\n", result.Models, result.Elapsed, triesSuffix(result.Tries))
+	return nil
+}
+
+func testAllProviders(args []string) error {
+	fs := flag.NewFlagSet("test-all", flag.ContinueOnError)
+	concurrency := fs.Int("concurrency", 4, "number of providers to test at once")
+	opts := addTestFlags(fs)
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := checkTestFlags(fs, opts); err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return fmt.Errorf("usage: test-all [--concurrency <n>] [--retries <n>] [--timeout <duration>]")
+	}
+	if *concurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+
+	configPath, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	if len(config.Provider) == 0 {
+		fmt.Println("No providers configured")
+		return nil
+	}
+
+	keys := config.ProviderKeys()
+
+	fmt.Printf("Testing %d provider(s)...\n", len(keys))
+
+	jobs := make(chan int)
+	results := make([]providerTestResult, len(keys))
+	var wg sync.WaitGroup
+	for w := 0; w < *concurrency && w < len(keys); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = testProviderConnection(keys[i], config.Provider[keys[i]], *opts)
+			}
+		}()
+	}
+	for i := range keys {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	failed := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w)
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+			fmt.Fprintf(w, "%s\t%s\t%s\t%v\n", result.Key, red("FAIL"), result.Elapsed, retryError(result.Err, result.Tries))
+		} else {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d model(s)%s\n", result.Key, green("OK"), result.Elapsed, result.Models, triesSuffix(result.Tries))
+		}
+	}
+	w.Flush()
+
+	fmt.Printf("\n%d passed, %d failed\n", len(results)-failed, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d provider(s) failed", failed)
+	}
+	return nil
+}
@@ -0,0 +1,324 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	ocfg "github.com/liamwilliams93/opencode-config-wizard/pkg/config"
+)
+
+// doctorIssue is a problem found by doctor. Issues without a fix are only
+// reported, since repairing them would mean guessing what the user wants.
+// Warnings may be intended, such as a built-in provider in enabled_providers,
+// so validate --check doesn't fail on them.
+type doctorIssue struct {
+	message string
+	fix     func(config *Config)
+	warning bool
+}
+
+// rawMCPTypes returns the type of each MCP server as written in the config
+// file, before aliases are normalized on load.
+func rawMCPTypes(path string) map[string]string {
+	types := make(map[string]string)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return types
+	}
+	data, err = ocfg.Decompress(data)
+	if err != nil {
+		return types
+	}
+	data, _ = ocfg.StripTrailingCommas(data)
+
+	var raw struct {
+		MCP map[string]struct {
+			Type string `json:"type"`
+		} `json:"mcp"`
+	}
+	if json.Unmarshal(data, &raw) == nil {
+		for name, server := range raw.MCP {
+			types[name] = server.Type
+		}
+	}
+	return types
+}
+
+// diagnoseConfig finds problems in config, which was loaded from path.
+func diagnoseConfig(config *Config, path string) []doctorIssue {
+	var issues []doctorIssue
+
+	// Parsing fills in a missing $schema, so look at the file itself.
+	schema, present := config.Schema, true
+	if data, err := os.ReadFile(path); err == nil {
+		if data, err = ocfg.Decompress(data); err == nil {
+			schema, present = schemaField(data)
+		}
+	}
+	switch {
+	case !present:
+		issues = append(issues, doctorIssue{
+			message: fmt.Sprintf("$schema is missing, expected '%s'", ocfg.SchemaURL),
+			fix:     func(c *Config) { c.Schema = ocfg.SchemaURL },
+		})
+	case schema != ocfg.SchemaURL:
+		issues = append(issues, doctorIssue{
+			message: fmt.Sprintf("$schema is '%s', expected '%s'", schema, ocfg.SchemaURL),
+			fix:     func(c *Config) { c.Schema = ocfg.SchemaURL },
+		})
+	}
+
+	if config.Model != "" {
+		if err := config.ValidateModelRef(config.Model); err != nil {
+			issues = append(issues, doctorIssue{
+				message: fmt.Sprintf("default model '%s' is dangling: %v", config.Model, err),
+				fix:     func(c *Config) { c.Model = "" },
+			})
+		}
+	}
+	if config.SmallModel != "" {
+		if err := config.ValidateModelRef(config.SmallModel); err != nil {
+			issues = append(issues, doctorIssue{
+				message: fmt.Sprintf("small model '%s' is dangling: %v", config.SmallModel, err),
+				fix:     func(c *Config) { c.SmallModel = "" },
+			})
+		}
+	}
+
+	var unknownEnabled []string
+	for _, key := range dedupeStrings(config.EnabledProviders) {
+		if _, exists := config.Provider[key]; !exists {
+			unknownEnabled = append(unknownEnabled, key)
+		}
+	}
+	if len(unknownEnabled) > 0 && len(unknownEnabled) == len(dedupeStrings(config.EnabledProviders)) {
+		// Emptying enabled_providers would make opencode load every
+		// provider, which is the opposite of what the list asks for.
+		issues = append(issues, doctorIssue{
+			message: fmt.Sprintf("enabled_providers only lists providers that aren't configured: %v", unknownEnabled),
+			warning: true,
+		})
+	} else {
+		for _, key := range unknownEnabled {
+			issues = append(issues, doctorIssue{
+				message: fmt.Sprintf("enabled_providers lists '%s', which isn't configured", key),
+				fix:     func(c *Config) { c.EnabledProviders = removeString(c.EnabledProviders, key) },
+				warning: true,
+			})
+		}
+	}
+	for _, key := range dedupeStrings(config.DisabledProviders) {
+		if _, exists := config.Provider[key]; !exists {
+			issues = append(issues, doctorIssue{
+				message: fmt.Sprintf("disabled_providers lists '%s', which isn't configured", key),
+				fix:     func(c *Config) { c.DisabledProviders = removeString(c.DisabledProviders, key) },
+				warning: true,
+			})
+		}
+	}
+
+	names := make([]string, 0, len(config.MCP))
+	for name := range config.MCP {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	rawTypes := rawMCPTypes(path)
+	for _, name := range names {
+		rawType := rawTypes[name]
+		switch canonical, isAlias := ocfg.MCPTypeAliases[rawType]; {
+		case isAlias:
+			// Aliases are already normalized on load, so saving is the fix.
+			issues = append(issues, doctorIssue{
+				message: fmt.Sprintf("MCP server '%s' has type '%s' instead of '%s'", name, rawType, canonical),
+				fix:     func(c *Config) {},
+			})
+		case config.MCP[name].Type != "local" && config.MCP[name].Type != "remote":
+			issues = append(issues, doctorIssue{
+				message: fmt.Sprintf("MCP server '%s' has unknown type '%s'", name, config.MCP[name].Type),
+			})
+		}
+	}
+
+	for _, key := range config.EmptyProviders() {
+		issues = append(issues, doctorIssue{
+			message: fmt.Sprintf("provider '%s' has no models", key),
+			warning: true,
+		})
+	}
+
+	issues = append(issues, limitMismatches(config)...)
+	issues = append(issues, duplicateNames(config)...)
+
+	return issues
+}
+
+// duplicateNames reports providers that share a display name, and models
+// within a provider that do, since listings can't tell them apart. Names
+// are only compared when set.
+func duplicateNames(config *Config) []doctorIssue {
+	var issues []doctorIssue
+
+	// quoted joins keys, which are already sorted, as 'a', 'b'.
+	quoted := func(keys []string) string {
+		return "'" + strings.Join(keys, "', '") + "'"
+	}
+	// shared returns the names given to more than one key, sorted.
+	shared := func(keysByName map[string][]string) []string {
+		var names []string
+		for name, keys := range keysByName {
+			if name != "" && len(keys) > 1 {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	providersByName := make(map[string][]string)
+	for _, key := range config.ProviderKeys() {
+		name := config.Provider[key].Name
+		providersByName[name] = append(providersByName[name], key)
+	}
+	for _, name := range shared(providersByName) {
+		issues = append(issues, doctorIssue{
+			message: fmt.Sprintf("providers %s share the display name '%s'; give them distinct names", quoted(providersByName[name]), name),
+			warning: true,
+		})
+	}
+
+	for _, key := range config.ProviderKeys() {
+		models := config.Provider[key].Models
+		modelIDs := make([]string, 0, len(models))
+		for id := range models {
+			modelIDs = append(modelIDs, id)
+		}
+		sort.Strings(modelIDs)
+
+		modelsByName := make(map[string][]string)
+		for _, id := range modelIDs {
+			modelsByName[models[id].Name] = append(modelsByName[models[id].Name], id)
+		}
+		for _, name := range shared(modelsByName) {
+			issues = append(issues, doctorIssue{
+				message: fmt.Sprintf("models %s in provider '%s' share the display name '%s'; give them distinct names", quoted(modelsByName[name]), key, name),
+				warning: true,
+			})
+		}
+	}
+
+	return issues
+}
+
+// limitMismatches reports model IDs configured under several providers with
+// different limits, which usually means a copy was edited and the others
+// weren't. Which limits are right can't be told, so there is no fix.
+func limitMismatches(config *Config) []doctorIssue {
+	providersByModel := make(map[string][]string)
+	for _, providerKey := range config.ProviderKeys() {
+		for modelID := range config.Provider[providerKey].Models {
+			providersByModel[modelID] = append(providersByModel[modelID], providerKey)
+		}
+	}
+
+	modelIDs := make([]string, 0, len(providersByModel))
+	for modelID, providerKeys := range providersByModel {
+		if len(providerKeys) > 1 {
+			modelIDs = append(modelIDs, modelID)
+		}
+	}
+	sort.Strings(modelIDs)
+
+	var issues []doctorIssue
+	for _, modelID := range modelIDs {
+		var limits []string
+		differ := false
+		var first ModelLimit
+		for i, providerKey := range providersByModel[modelID] {
+			var limit ModelLimit
+			if l := config.Provider[providerKey].Models[modelID].Limit; l != nil {
+				limit = *l
+			}
+			if i == 0 {
+				first = limit
+			} else if limit != first {
+				differ = true
+			}
+			limits = append(limits, fmt.Sprintf("%s (context %s, output %s)", providerKey, formatLimit(limit.Context), formatLimit(limit.Output)))
+		}
+		if differ {
+			issues = append(issues, doctorIssue{
+				message: fmt.Sprintf("model '%s' has different limits in %s", modelID, strings.Join(limits, ", ")),
+				warning: true,
+			})
+		}
+	}
+	return issues
+}
+
+func runDoctor(args []string) error {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	fix := fs.Bool("fix", false, "repair the problems that have an unambiguous fix")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return fmt.Errorf("usage: doctor [--fix]")
+	}
+
+	configPath, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		fmt.Println("No config file to check")
+		return nil
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	issues := diagnoseConfig(config, configPath)
+
+	fmt.Println("\n=== Doctor ===")
+	if len(issues) == 0 {
+		fmt.Println(green("No problems found"))
+		return nil
+	}
+
+	fixable := 0
+	for _, issue := range issues {
+		switch {
+		case issue.fix == nil:
+			fmt.Printf("  %s %s %s\n", yellow("!"), issue.message, dim("(needs a manual fix)"))
+		case *fix:
+			issue.fix(config)
+			fixable++
+			fmt.Printf("  %s %s\n", green("fixed"), issue.message)
+		default:
+			fixable++
+			fmt.Printf("  %s %s\n", red("x"), issue.message)
+		}
+	}
+
+	if fixable == 0 {
+		return nil
+	}
+	if !*fix {
+		return fmt.Errorf("config is %w: %d fixable problem(s); run 'doctor --fix' to repair them", ocfg.ErrInvalid, fixable)
+	}
+
+	if err := saveConfig(config, configPath); err != nil {
+		return err
+	}
+	fmt.Printf("\nApplied %d fix(es) to %s\n", fixable, configPath)
+	return nil
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// modelCacheTTL is how long a fetched model catalog is reused before the
+// provider is asked again.
+const modelCacheTTL = 24 * time.Hour
+
+var envReferencePattern = regexp.MustCompile(`\{env:([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// resolveSecret expands opencode's {env:VAR} references and ${VAR}
+// placeholders in value, returning any variables that are unset.
+func resolveSecret(value string) (string, []string) {
+	var missing []string
+	value = envReferencePattern.ReplaceAllStringFunc(value, func(match string) string {
+		name := envReferencePattern.FindStringSubmatch(match)[1]
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return v
+	})
+	value, unset := expandPlaceholders(value)
+	return value, append(missing, unset...)
+}
+
+// newProviderRequest builds a GET request for path under the provider's base
+// URL, with its API key and headers applied.
+func newProviderRequest(provider Provider, path string) (*http.Request, error) {
+	baseURL, _ := provider.Options["baseURL"].(string)
+	if baseURL == "" {
+		return nil, fmt.Errorf("no base URL configured")
+	}
+
+	req, err := http.NewRequest(http.MethodGet, strings.TrimRight(baseURL, "/")+path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	if apiKey, ok := provider.Options["apiKey"].(string); ok && apiKey != "" {
+		value, unset := resolveSecret(apiKey)
+		missing = append(missing, unset...)
+		req.Header.Set("Authorization", "Bearer "+value)
+	}
+	for name, value := range providerHeaders(provider) {
+		value, unset := resolveSecret(value)
+		missing = append(missing, unset...)
+		req.Header.Set(name, value)
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("API key or headers reference unset variable(s): %s", strings.Join(missing, ", "))
+	}
+	return req, nil
+}
+
+// fetchProviderModels asks an OpenAI-compatible provider for its model IDs.
+func fetchProviderModels(provider Provider, timeout time.Duration) ([]string, error) {
+	req, err := newProviderRequest(provider, "/models")
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := newHTTPClient(timeout)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server responded with %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var catalog struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &catalog); err != nil {
+		return nil, fmt.Errorf("unexpected /models response: %w", err)
+	}
+
+	ids := make([]string, 0, len(catalog.Data))
+	for _, entry := range catalog.Data {
+		if entry.ID != "" {
+			ids = append(ids, entry.ID)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// modelCatalog is the cached result of fetching a provider's models. The
+// base URL they came from is kept so the cache is ignored once the provider
+// points somewhere else.
+type modelCatalog struct {
+	FetchedAt time.Time `json:"fetched_at"`
+	BaseURL   string    `json:"base_url"`
+	Models    []string  `json:"models"`
+}
+
+func getModelCachePath(providerKey string) (string, error) {
+	cacheDir, err := getCacheDir()
+	if err != nil {
+		return "", err
+	}
+	name := strings.NewReplacer("/", "_", `\`, "_").Replace(providerKey)
+	return filepath.Join(cacheDir, name+".json"), nil
+}
+
+// cachedProviderModels returns the provider's model IDs from the cache if it
+// is younger than modelCacheTTL and was fetched from the provider's current
+// base URL, fetching and caching them otherwise. It reports whether the cache
+// was used.
+func cachedProviderModels(providerKey string, provider Provider, refresh bool) ([]string, bool, error) {
+	cachePath, err := getModelCachePath(providerKey)
+	if err != nil {
+		return nil, false, err
+	}
+	baseURL, _ := provider.Options["baseURL"].(string)
+
+	if !refresh {
+		if data, err := os.ReadFile(cachePath); err == nil {
+			var catalog modelCatalog
+			if json.Unmarshal(data, &catalog) == nil && catalog.BaseURL == baseURL && time.Since(catalog.FetchedAt) < modelCacheTTL {
+				return catalog.Models, true, nil
+			}
+		}
+	}
+
+	ids, err := fetchProviderModels(provider, defaultTestTimeout)
+	if err != nil {
+		return nil, false, err
+	}
+
+	data, err := json.MarshalIndent(modelCatalog{FetchedAt: time.Now(), BaseURL: baseURL, Models: ids}, "", "  ")
+	if err != nil {
+		return nil, false, err
+	}
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0700); err == nil {
+		// A cache that can't be written only costs a refetch next time.
+		os.WriteFile(cachePath, data, 0600)
+	}
+	return ids, false, nil
+}
+
+func fetchModels(args []string) error {
+	fs := flag.NewFlagSet("fetch-models", flag.ContinueOnError)
+	refresh := fs.Bool("refresh", false, "ignore the cached catalog and ask the provider again")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 1 {
+		return fmt.Errorf("usage: fetch-models [--refresh] [provider]")
+	}
+
+	configPath, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	if len(config.Provider) == 0 {
+		fmt.Println("No providers configured. Use 'add' command first.")
+		return nil
+	}
+
+	var selector string
+	if len(positional) == 1 {
+		selector = positional[0]
+	}
+
+	providerKey, err := selectProvider(config, selector, "\n=== Fetch Models ===")
+	if err != nil {
+		return err
+	}
+	provider := config.Provider[providerKey]
+
+	ids, cached, err := cachedProviderModels(providerKey, provider, *refresh)
+	if err != nil {
+		return err
+	}
+
+	if len(ids) == 0 {
+		fmt.Printf("Provider '%s' reported no models\n", providerKey)
+		return nil
+	}
+
+	source := "from provider"
+	if cached {
+		source = "cached; use --refresh to fetch again"
+	}
+	fmt.Printf("\nModels available from %s (%s):\n", providerKey, source)
+	for i, id := range ids {
+		if _, configured := provider.Models[id]; configured {
+			fmt.Printf("  %d. %s %s\n", i+1, id, dim("(configured)"))
+		} else {
+			fmt.Printf("  %d. %s\n", i+1, id)
+		}
+	}
+
+	selection := promptString("\nModels to add (numbers or IDs, comma-separated, blank to skip)", "")
+	if selection == "" {
+		return nil
+	}
+
+	if provider.Models == nil {
+		provider.Models = make(map[string]Model)
+	}
+
+	added := addSelectedModels(provider.Models, ids, selection)
+
+	if added == 0 {
+		return nil
+	}
+
+	config.Provider[providerKey] = provider
+	if err := saveConfig(config, configPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("Added %d model(s) to provider '%s'\n", added, providerKey)
+	return nil
+}
+
+// addSelectedModels adds the models picked by selection, a comma-separated
+// list of numbers into ids or model IDs, filling in known limits. Models that
+// are already configured are skipped. It returns how many were added.
+func addSelectedModels(models map[string]Model, ids []string, selection string) int {
+	added := 0
+	for _, item := range strings.Split(selection, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		id := resolveSelection(item, ids)
+		if _, configured := models[id]; configured {
+			fmt.Printf("Skipping '%s': already configured\n", id)
+			continue
+		}
+
+		model := Model{Name: id}
+		if limit, _, ok := lookupModelLimit(id); ok {
+			model.Limit = &ModelLimit{Context: limit.Context, Output: limit.Output}
+		}
+		models[id] = model
+		added++
+	}
+	return added
+}
+
+// cacheCommand manages the cache of downloaded data.
+func cacheCommand(args []string) error {
+	if len(args) != 1 || args[0] != "clear" {
+		return fmt.Errorf("usage: cache clear")
+	}
+
+	cacheDir, err := getCacheDir()
+	if err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(cacheDir); err != nil {
+		return err
+	}
+
+	fmt.Printf("Cleared cache: %s\n", cacheDir)
+	return nil
+}
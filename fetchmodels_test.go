@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestCachedProviderModelsFollowsBaseURL(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	serve := func(id string) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"data": [{"id": %q}]}`, id)
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+	first, second := serve("qwen3-coder"), serve("gpt-oss")
+
+	for _, tc := range []struct {
+		baseURL    string
+		want       []string
+		wantCached bool
+	}{
+		{first.URL, []string{"qwen3-coder"}, false},
+		{first.URL, []string{"qwen3-coder"}, true},
+		{second.URL, []string{"gpt-oss"}, false},
+		{second.URL, []string{"gpt-oss"}, true},
+	} {
+		provider := Provider{Name: "Local", Options: map[string]interface{}{"baseURL": tc.baseURL}}
+		ids, cached, err := cachedProviderModels("local", provider, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(ids, tc.want) || cached != tc.wantCached {
+			t.Errorf("%s: got %v (cached %v), want %v (cached %v)", tc.baseURL, ids, cached, tc.want, tc.wantCached)
+		}
+	}
+}
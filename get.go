@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+)
+
+// formatValue renders a value read from the config. By default strings are
+// printed without quotes and objects and arrays as indented JSON; asRaw keeps
+// every value on one line, and asJSON quotes strings too.
+func formatValue(value interface{}, asRaw, asJSON bool) (string, error) {
+	if s, ok := value.(string); ok && !asJSON {
+		return s, nil
+	}
+
+	var data []byte
+	var err error
+	if asRaw {
+		data, err = json.Marshal(value)
+	} else {
+		data, err = json.MarshalIndent(value, "", "  ")
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func getConfigValue(args []string) error {
+	fs := flag.NewFlagSet("get", flag.ContinueOnError)
+	asRaw := fs.Bool("raw", false, "print strings unquoted and objects as single-line JSON")
+	asJSON := fs.Bool("json", false, "print the value as JSON, quoting strings too")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("usage: get [--raw|--json] <path>")
+	}
+	if *asRaw && *asJSON {
+		return fmt.Errorf("--raw and --json can't be used together")
+	}
+
+	tokens, err := parsePointer(positional[0])
+	if err != nil {
+		return err
+	}
+
+	configPath, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	raw, err := loadRawConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	value, err := pointerGet(raw, tokens)
+	if err != nil {
+		return err
+	}
+
+	output, err := formatValue(value, *asRaw, *asJSON)
+	if err != nil {
+		return err
+	}
+	fmt.Println(output)
+	return nil
+}
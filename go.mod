@@ -1,3 +1,10 @@
 module github.com/liamwilliams93/opencode-config-wizard
 
 go 1.25.6
+
+require (
+	github.com/santhosh-tekuri/jsonschema/v5 v5.3.1
+	golang.org/x/term v0.40.0
+)
+
+require golang.org/x/sys v0.41.0 // indirect
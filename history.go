@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// noLog disables the change history log; set by the --no-log flag.
+var noLog bool
+
+// getHistoryPath returns the change log kept alongside the backups of the
+// config at path.
+func getHistoryPath(path string) string {
+	return filepath.Join(filepath.Dir(getBackupDir(path)), "wizard.log")
+}
+
+// sameJSON reports whether a and b encode to the same JSON.
+func sameJSON(a, b interface{}) bool {
+	aData, aErr := json.Marshal(a)
+	bData, bErr := json.Marshal(b)
+	return aErr == nil && bErr == nil && bytes.Equal(aData, bData)
+}
+
+// sortedDiffKeys returns the keys that are only in before, only in after, and
+// in both, each sorted.
+func sortedDiffKeys[V any](before, after map[string]V) (removed, added, common []string) {
+	for key := range before {
+		if _, ok := after[key]; ok {
+			common = append(common, key)
+		} else {
+			removed = append(removed, key)
+		}
+	}
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			added = append(added, key)
+		}
+	}
+	sort.Strings(removed)
+	sort.Strings(added)
+	sort.Strings(common)
+	return removed, added, common
+}
+
+// describeChanges summarizes how after differs from before. Only names are
+// mentioned, never option, header or environment values, so secrets stay out
+// of the log.
+func describeChanges(before, after *Config) []string {
+	var changes []string
+
+	removed, added, common := sortedDiffKeys(before.Provider, after.Provider)
+	for _, key := range added {
+		changes = append(changes, fmt.Sprintf("provider added: %s", key))
+	}
+	for _, key := range removed {
+		changes = append(changes, fmt.Sprintf("provider deleted: %s", key))
+	}
+	for _, key := range common {
+		oldProvider, newProvider := before.Provider[key], after.Provider[key]
+
+		removedModels, addedModels, commonModels := sortedDiffKeys(oldProvider.Models, newProvider.Models)
+		for _, id := range addedModels {
+			changes = append(changes, fmt.Sprintf("model added: %s/%s", key, id))
+		}
+		for _, id := range removedModels {
+			changes = append(changes, fmt.Sprintf("model deleted: %s/%s", key, id))
+		}
+		for _, id := range commonModels {
+			if !sameJSON(oldProvider.Models[id], newProvider.Models[id]) {
+				changes = append(changes, fmt.Sprintf("model changed: %s/%s", key, id))
+			}
+		}
+
+		removedOptions, addedOptions, commonOptions := sortedDiffKeys(oldProvider.Options, newProvider.Options)
+		for _, option := range addedOptions {
+			changes = append(changes, fmt.Sprintf("option set: %s.%s", key, option))
+		}
+		for _, option := range removedOptions {
+			changes = append(changes, fmt.Sprintf("option deleted: %s.%s", key, option))
+		}
+		for _, option := range commonOptions {
+			if !sameJSON(oldProvider.Options[option], newProvider.Options[option]) {
+				changes = append(changes, fmt.Sprintf("option changed: %s.%s", key, option))
+			}
+		}
+
+		if oldProvider.Name != newProvider.Name || oldProvider.NPM != newProvider.NPM {
+			changes = append(changes, fmt.Sprintf("provider changed: %s", key))
+		}
+	}
+
+	removed, added, common = sortedDiffKeys(before.MCP, after.MCP)
+	for _, name := range added {
+		changes = append(changes, fmt.Sprintf("MCP server added: %s", name))
+	}
+	for _, name := range removed {
+		changes = append(changes, fmt.Sprintf("MCP server deleted: %s", name))
+	}
+	for _, name := range common {
+		if !sameJSON(before.MCP[name], after.MCP[name]) {
+			changes = append(changes, fmt.Sprintf("MCP server changed: %s", name))
+		}
+	}
+
+	if before.Schema != after.Schema {
+		changes = append(changes, fmt.Sprintf("$schema: %q -> %q", before.Schema, after.Schema))
+	}
+	if before.Model != after.Model {
+		changes = append(changes, fmt.Sprintf("default model: %q -> %q", before.Model, after.Model))
+	}
+	if before.SmallModel != after.SmallModel {
+		changes = append(changes, fmt.Sprintf("small model: %q -> %q", before.SmallModel, after.SmallModel))
+	}
+	if !sameJSON(before.EnabledProviders, after.EnabledProviders) {
+		changes = append(changes, "enabled_providers changed")
+	}
+	if !sameJSON(before.DisabledProviders, after.DisabledProviders) {
+		changes = append(changes, "disabled_providers changed")
+	}
+
+	return changes
+}
+
+// logConfigChange appends a line per change to the history log of the config
+// at path. Logging is best effort: a failure is reported but never stops the
+// save.
+func logConfigChange(path string, before, after *Config) {
+	if noLog {
+		return
+	}
+
+	changes := describeChanges(before, after)
+	if len(changes) == 0 {
+		return
+	}
+
+	username := "unknown"
+	if u, err := user.Current(); err == nil {
+		username = u.Username
+	}
+
+	command := currentCommand
+	if command == "" {
+		command = "-"
+	}
+
+	var entry strings.Builder
+	timestamp := time.Now().Format(time.RFC3339)
+	for _, change := range changes {
+		fmt.Fprintf(&entry, "%s\t%s\t%s\t%s\n", timestamp, username, command, change)
+	}
+
+	historyPath := getHistoryPath(path)
+	if err := os.MkdirAll(filepath.Dir(historyPath), 0700); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not write history log: %v\n", err)
+		return
+	}
+
+	f, err := os.OpenFile(historyPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not write history log: %v\n", err)
+		return
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(entry.String()); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not write history log: %v\n", err)
+	}
+}
+
+func showHistory(args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("usage: history")
+	}
+
+	configPath, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(getHistoryPath(configPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			fmt.Println("No changes recorded")
+			return nil
+		}
+		return err
+	}
+
+	fmt.Println("\n=== Change History ===")
+	for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+		fields := strings.SplitN(line, "\t", 4)
+		if len(fields) != 4 {
+			fmt.Println(line)
+			continue
+		}
+		when := fields[0]
+		if t, err := time.Parse(time.RFC3339, fields[0]); err == nil {
+			when = t.Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%s  %s  %s  %s\n", dim(when), fields[1], fields[2], fields[3])
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	ocfg "github.com/liamwilliams93/opencode-config-wizard/pkg/config"
+)
+
+// readModelsFile reads models from a CSV file with id,name,context,output
+// columns or from a JSON array of model objects keyed by their "id" field.
+func readModelsFile(path string) (map[string]Model, []string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".json" || (ext != ".csv" && bytes.HasPrefix(bytes.TrimSpace(data), []byte("["))) {
+		return parseModelsJSON(data)
+	}
+	return parseModelsCSV(data)
+}
+
+func parseModelsJSON(data []byte) (map[string]Model, []string, error) {
+	var list []Model
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, nil, fmt.Errorf("%w JSON model list: %w", ocfg.ErrInvalid, err)
+	}
+
+	models := make(map[string]Model)
+	order := []string{}
+	for i, model := range list {
+		if model.ID == "" {
+			return nil, nil, fmt.Errorf("%w model %d: it has no id", ocfg.ErrInvalid, i+1)
+		}
+		if model.Limit != nil && (model.Limit.Context < 0 || model.Limit.Output < 0) {
+			return nil, nil, fmt.Errorf("%w limit for model '%s': limits must be positive", ocfg.ErrInvalid, model.ID)
+		}
+		id := model.ID
+		model.ID = ""
+		if model.Name == "" {
+			model.Name = id
+		}
+		if _, exists := models[id]; !exists {
+			order = append(order, id)
+		}
+		models[id] = model
+	}
+	return models, order, nil
+}
+
+func parseModelsCSV(data []byte) (map[string]Model, []string, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	models := make(map[string]Model)
+	order := []string{}
+	line := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w CSV: %w", ocfg.ErrInvalid, err)
+		}
+		line++
+
+		if len(record) == 0 || strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+		if line == 1 && strings.EqualFold(strings.TrimSpace(record[0]), "id") {
+			continue
+		}
+
+		id := strings.TrimSpace(record[0])
+		model := Model{Name: id}
+		if len(record) > 1 && strings.TrimSpace(record[1]) != "" {
+			model.Name = strings.TrimSpace(record[1])
+		}
+
+		limit := &ModelLimit{}
+		if len(record) > 2 && strings.TrimSpace(record[2]) != "" {
+			if limit.Context, err = strconv.Atoi(strings.TrimSpace(record[2])); err != nil || limit.Context <= 0 {
+				return nil, nil, fmt.Errorf("line %d: %w context limit '%s': use a positive whole number", line, ocfg.ErrInvalid, record[2])
+			}
+		}
+		if len(record) > 3 && strings.TrimSpace(record[3]) != "" {
+			if limit.Output, err = strconv.Atoi(strings.TrimSpace(record[3])); err != nil || limit.Output <= 0 {
+				return nil, nil, fmt.Errorf("line %d: %w output limit '%s': use a positive whole number", line, ocfg.ErrInvalid, record[3])
+			}
+		}
+		if limit.Context > 0 || limit.Output > 0 {
+			model.Limit = limit
+		}
+
+		if _, exists := models[id]; !exists {
+			order = append(order, id)
+		}
+		models[id] = model
+	}
+	return models, order, nil
+}
+
+func importModels(args []string) error {
+	fs := flag.NewFlagSet("import-models", flag.ContinueOnError)
+	overwriteFlag := fs.Bool("overwrite", false, "replace models that already exist without asking")
+	skipExisting := fs.Bool("skip-existing", false, "keep models that already exist without asking")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if (len(args) != 0 && len(args) != 2) || (*overwriteFlag && *skipExisting) {
+		return fmt.Errorf("usage: import-models [--overwrite | --skip-existing] <provider> <file>")
+	}
+
+	configPath, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	var providerKey, file string
+	if len(args) == 2 {
+		providerKey, file = args[0], args[1]
+		if _, exists := config.Provider[providerKey]; !exists {
+			return fmt.Errorf("provider '%s' %w", providerKey, ocfg.ErrNotFound)
+		}
+	} else {
+		if len(config.Provider) == 0 {
+			fmt.Println("No providers configured. Use 'add' command first.")
+			return nil
+		}
+
+		fmt.Println("\n=== Import Models ===")
+
+		providerKey = promptProviderKey(config)
+		if providerKey == "" {
+			return errCancelled
+		}
+		if _, exists := config.Provider[providerKey]; !exists {
+			return fmt.Errorf("provider '%s' %w", providerKey, ocfg.ErrNotFound)
+		}
+
+		file = promptString("File to import (CSV or JSON)", "")
+		if file == "" {
+			return errCancelled
+		}
+	}
+
+	models, order, err := readModelsFile(file)
+	if err != nil {
+		return err
+	}
+	if len(models) == 0 {
+		fmt.Println("No models found in file")
+		return nil
+	}
+
+	provider := config.Provider[providerKey]
+	if provider.Models == nil {
+		provider.Models = make(map[string]Model)
+	}
+
+	existing := []string{}
+	for _, id := range order {
+		if _, exists := provider.Models[id]; exists {
+			existing = append(existing, id)
+		}
+	}
+
+	overwrite := *overwriteFlag
+	if len(existing) > 0 {
+		fmt.Printf("%d model(s) already exist: %s\n", len(existing), strings.Join(existing, ", "))
+		if !*overwriteFlag && !*skipExisting {
+			overwrite = promptBool("Overwrite existing models?", false)
+		}
+	}
+
+	added, skipped := 0, 0
+	for _, id := range order {
+		if _, exists := provider.Models[id]; exists && !overwrite {
+			skipped++
+			continue
+		}
+		provider.Models[id] = models[id]
+		added++
+	}
+	config.Provider[providerKey] = provider
+
+	if added > 0 {
+		if err := saveConfig(config, configPath); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Imported %d model(s) into provider '%s', skipped %d\n", added, providerKey, skipped)
+	return nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	ocfg "github.com/liamwilliams93/opencode-config-wizard/pkg/config"
+)
+
+func TestParseModelsCSV(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		input     string
+		wantOrder []string
+		wantModel map[string]Model
+	}{
+		{
+			name:      "header and limits",
+			input:     "id,name,context,output\nqwen3-coder,Qwen 3 Coder,128000,65536\nllama3,Llama 3,8192,\n",
+			wantOrder: []string{"qwen3-coder", "llama3"},
+			wantModel: map[string]Model{
+				"qwen3-coder": {Name: "Qwen 3 Coder", Limit: &ModelLimit{Context: 128000, Output: 65536}},
+				"llama3":      {Name: "Llama 3", Limit: &ModelLimit{Context: 8192}},
+			},
+		},
+		{
+			name:      "ID only",
+			input:     "gpt-oss\n",
+			wantOrder: []string{"gpt-oss"},
+			wantModel: map[string]Model{"gpt-oss": {Name: "gpt-oss"}},
+		},
+		{
+			name:      "duplicate ID keeps first position",
+			input:     "a,First\nb\na,Second\n",
+			wantOrder: []string{"a", "b"},
+			wantModel: map[string]Model{"a": {Name: "Second"}, "b": {Name: "b"}},
+		},
+	} {
+		models, order, err := parseModelsCSV([]byte(tc.input))
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(order, tc.wantOrder) {
+			t.Errorf("%s: order %v, want %v", tc.name, order, tc.wantOrder)
+		}
+		if !reflect.DeepEqual(models, tc.wantModel) {
+			t.Errorf("%s: models %+v, want %+v", tc.name, models, tc.wantModel)
+		}
+	}
+}
+
+func TestParseModelsErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		parse func([]byte) (map[string]Model, []string, error)
+		input string
+	}{
+		{"CSV zero context", parseModelsCSV, "a,A,0,100\n"},
+		{"CSV negative output", parseModelsCSV, "a,A,100,-1\n"},
+		{"CSV non-numeric context", parseModelsCSV, "a,A,lots\n"},
+		{"JSON missing id", parseModelsJSON, `[{"name": "A"}]`},
+		{"JSON negative limit", parseModelsJSON, `[{"id": "a", "limit": {"context": -1}}]`},
+		{"JSON not a list", parseModelsJSON, `{"id": "a"}`},
+	} {
+		if _, _, err := tc.parse([]byte(tc.input)); !errors.Is(err, ocfg.ErrInvalid) {
+			t.Errorf("%s: error %v, want ErrInvalid", tc.name, err)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	ocfg "github.com/liamwilliams93/opencode-config-wizard/pkg/config"
+)
+
+// starterConfig returns the config written by init: a local Ollama provider
+// and a disabled example MCP server. The config is plain JSON, so the
+// examples are real entries rather than comments; the MCP server stays off
+// until it is enabled.
+func starterConfig() *Config {
+	config := ocfg.New()
+
+	config.Provider["ollama"] = Provider{
+		NPM:     "@ai-sdk/openai-compatible",
+		Name:    "Ollama (local)",
+		Options: map[string]interface{}{"baseURL": "http://localhost:11434/v1"},
+		Models: map[string]Model{
+			"qwen3-coder": {Name: "Qwen3 Coder"},
+		},
+	}
+
+	enabled := false
+	config.MCP["filesystem"] = MCPServer{
+		Type:    "local",
+		Command: []string{"npx", "-y", "@modelcontextprotocol/server-filesystem", "."},
+		Enabled: &enabled,
+	}
+
+	return config
+}
+
+func initConfig(args []string) error {
+	fs := flag.NewFlagSet("init", flag.ContinueOnError)
+	force := fs.Bool("force", false, "replace an existing config (it is backed up first)")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return fmt.Errorf("usage: init [--force]")
+	}
+
+	configPath, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(configPath); err == nil && !*force {
+		return fmt.Errorf("%s already exists; use --force to replace it", configPath)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(configPath), 0700); err != nil {
+		return err
+	}
+
+	if err := saveConfig(starterConfig(), configPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("Created starter config: %s\n", configPath)
+	fmt.Println("\nIt contains:")
+	fmt.Println("  - an 'ollama' provider pointing at http://localhost:11434/v1 with one example model")
+	fmt.Println("  - a 'filesystem' MCP server with \"enabled\": false; set it to true to use it")
+	fmt.Println("\nNext steps:")
+	fmt.Println("  fetch-models ollama     pick the models your Ollama server actually has")
+	fmt.Println("  set-default             choose the model opencode starts with")
+	fmt.Println("  add                     add another provider")
+	fmt.Println("  add-mcp                 add MCP servers (see mcp-templates)")
+	fmt.Println("  validate                check the config against the schema")
+	return nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// jsonErrors makes runCommand report failures, and the outcome of commands
+// that write a config, as JSON for programs driving the wizard; set by the
+// --json-errors flag.
+var jsonErrors bool
+
+// errorEnvelope is printed to stderr instead of the "Error:" line when
+// jsonErrors is set.
+type errorEnvelope struct {
+	Error string `json:"error"`
+	Code  int    `json:"code"`
+}
+
+// commandResult is printed to stdout after a command that wrote a config
+// succeeds, when jsonErrors is set.
+type commandResult struct {
+	OK      bool     `json:"ok"`
+	Command string   `json:"command"`
+	Saved   []string `json:"saved"`
+	Changes []string `json:"changes,omitempty"`
+}
+
+// savedConfigs collects what the current command wrote, for the result.
+var savedConfigs commandResult
+
+// recordSave notes that saveConfig wrote path. before is nil unless path is
+// the active config, whose changes are then described.
+func recordSave(path string, before, after *Config) {
+	if !containsString(savedConfigs.Saved, path) {
+		savedConfigs.Saved = append(savedConfigs.Saved, path)
+	}
+	if before != nil {
+		savedConfigs.Changes = append(savedConfigs.Changes, describeChanges(before, after)...)
+	}
+}
+
+func printJSONError(err error) {
+	data, _ := json.Marshal(errorEnvelope{Error: err.Error(), Code: exitCode(err)})
+	fmt.Fprintln(os.Stderr, string(data))
+}
+
+// printJSONResult prints the result of a successful command, if it wrote a
+// config.
+func printJSONResult(name string) {
+	if len(savedConfigs.Saved) == 0 {
+		return
+	}
+	savedConfigs.OK = true
+	savedConfigs.Command = name
+	data, _ := json.Marshal(savedConfigs)
+	fmt.Println(string(data))
+}
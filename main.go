@@ -1,11 +1,14 @@
 package main
 
 import (
-	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io/fs"
 	"os"
-	"strconv"
-	"strings"
+
+	ocfg "github.com/liamwilliams93/opencode-config-wizard/pkg/config"
 )
 
 func showMainMenu() {
@@ -20,11 +23,15 @@ func showProviderMenu() {
 	fmt.Println("\nProvider Commands")
 	fmt.Println()
 	fmt.Println("1. List all configured providers")
-	fmt.Println("2. Add a new OpenAI-compatible provider")
+	fmt.Println("2. Add a new provider")
 	fmt.Println("3. Add a model to an existing provider")
 	fmt.Println("4. Delete a provider")
 	fmt.Println("5. Delete a model from a provider")
 	fmt.Println("6. Set default model")
+	fmt.Println("7. Set a provider option")
+	fmt.Println("8. Delete a provider option")
+	fmt.Println("9. Purge disabled providers")
+	fmt.Println("10. Import models from a file")
 	fmt.Println("0. Back to main menu")
 }
 
@@ -34,60 +41,106 @@ func showMCPMenu() {
 	fmt.Println("1. List all configured MCP servers")
 	fmt.Println("2. Add a new MCP server")
 	fmt.Println("3. Delete an MCP server")
+	fmt.Println("4. Edit MCP server environment variables")
+	fmt.Println("5. Test an MCP server")
+	fmt.Println("6. Edit MCP server OAuth settings")
+	fmt.Println("7. Clone an MCP server")
+	fmt.Println("8. List MCP server templates")
+	fmt.Println("9. Set an MCP server's timeout")
 	fmt.Println("0. Back to main menu")
 }
 
-func getMenuChoice(maxOption int) int {
-	fmt.Print("\nEnter choice: ")
-	scanner := bufio.NewScanner(os.Stdin)
-	scanner.Scan()
-	input := strings.TrimSpace(scanner.Text())
-
-	if input == "" {
-		return -1
+// parseFlags parses args with fs, allowing flags to appear before or after
+// positional arguments, and returns the positional arguments.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
 	}
+}
 
-	choice, err := strconv.Atoi(input)
-	if err != nil {
-		return -1
-	}
+// Exit codes returned for each category of failure, so scripts can tell
+// them apart.
+const (
+	exitFailure   = 1
+	exitNotFound  = 2
+	exitInvalid   = 3
+	exitIO        = 4
+	exitCancelled = 5
+)
 
-	if choice < 0 || choice > maxOption {
-		return -1
-	}
+// errCancelled is returned by commands when the user declines a confirmation
+// or leaves a required prompt blank.
+var errCancelled = errors.New("cancelled")
 
-	return choice
+// exitCode returns the exit code for an error returned by a command.
+func exitCode(err error) int {
+	var parseErr *ocfg.ParseError
+	var syntaxErr *json.SyntaxError
+	var pathErr *fs.PathError
+	var linkErr *os.LinkError
+	switch {
+	case errors.Is(err, errCancelled):
+		return exitCancelled
+	case errors.Is(err, ocfg.ErrNotFound), errors.Is(err, fs.ErrNotExist):
+		return exitNotFound
+	case errors.Is(err, ocfg.ErrInvalid), errors.As(err, &parseErr), errors.As(err, &syntaxErr):
+		return exitInvalid
+	case errors.As(err, &pathErr), errors.As(err, &linkErr):
+		return exitIO
+	}
+	return exitFailure
 }
 
-func executeWithErrorHandling(fn func() error) {
+func executeWithErrorHandling(name string) {
+	currentCommand = name
+	configBackedUp = false
+
 	fmt.Println()
-	if err := fn(); err != nil {
+	if err := commands[name](nil); errors.Is(err, errCancelled) {
+		fmt.Println("Cancelled")
+	} else if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v", err)
 	}
-	fmt.Println("\nPress Enter to continue...")
-	bufio.NewScanner(os.Stdin).Scan()
+	readLine("\nPress Enter to continue...")
 }
 
 func runProviderMenu() {
 	for {
 		showProviderMenu()
-		choice := getMenuChoice(6)
+		choice := getMenuChoice(10)
 
 		switch choice {
 		case 0:
 			return
 		case 1:
-			executeWithErrorHandling(listProviders)
+			executeWithErrorHandling("list")
 		case 2:
-			executeWithErrorHandling(addProvider)
+			executeWithErrorHandling("add")
 		case 3:
-			executeWithErrorHandling(addModel)
+			executeWithErrorHandling("add-model")
 		case 4:
-			executeWithErrorHandling(deleteProvider)
+			executeWithErrorHandling("delete")
 		case 5:
-			executeWithErrorHandling(deleteModel)
+			executeWithErrorHandling("delete-model")
 		case 6:
-			executeWithErrorHandling(setDefaultModel)
+			executeWithErrorHandling("set-default")
+		case 7:
+			executeWithErrorHandling("set-option")
+		case 8:
+			executeWithErrorHandling("delete-option")
+		case 9:
+			executeWithErrorHandling("purge-disabled")
+		case 10:
+			executeWithErrorHandling("import-models")
 		default:
 			fmt.Println("\nInvalid choice, please try again")
 		}
@@ -97,29 +150,314 @@ func runProviderMenu() {
 func runMCPMenu() {
 	for {
 		showMCPMenu()
-		choice := getMenuChoice(3)
+		choice := getMenuChoice(9)
 
 		switch choice {
 		case 0:
 			return
 		case 1:
-			executeWithErrorHandling(listMCPServers)
+			executeWithErrorHandling("list-mcp")
 		case 2:
-			executeWithErrorHandling(addMCPServer)
+			executeWithErrorHandling("add-mcp")
 		case 3:
-			executeWithErrorHandling(deleteMCPServer)
+			executeWithErrorHandling("delete-mcp")
+		case 4:
+			executeWithErrorHandling("mcp-env")
+		case 5:
+			executeWithErrorHandling("test-mcp")
+		case 6:
+			executeWithErrorHandling("mcp-oauth")
+		case 7:
+			executeWithErrorHandling("clone-mcp")
+		case 8:
+			executeWithErrorHandling("mcp-templates")
+		case 9:
+			executeWithErrorHandling("mcp-timeout")
 		default:
 			fmt.Println("\nInvalid choice, please try again")
 		}
 	}
 }
 
+var commands = map[string]func(args []string) error{
+	"add":                  addProvider,
+	"add-model":            addModel,
+	"list":                 listProviders,
+	"delete":               deleteProvider,
+	"delete-model":         deleteModel,
+	"set-default":          setDefaultModel,
+	"set-option":           setProviderOption,
+	"delete-option":        deleteProviderOption,
+	"purge-disabled":       purgeDisabledProviders,
+	"import-models":        importModels,
+	"add-mcp":              addMCPServer,
+	"list-mcp":             listMCPServers,
+	"delete-mcp":           deleteMCPServer,
+	"mcp-env":              editMCPEnvironment,
+	"test-mcp":             testMCPServer,
+	"mcp-oauth":            editMCPOAuth,
+	"clone-mcp":            cloneMCP,
+	"mcp-templates":        listMCPTemplates,
+	"migrate":              migrateConfig,
+	"clone-config":         cloneConfig,
+	"import":               importConfig,
+	"print-path":           printConfigPath,
+	"profile":              profileCommand,
+	"undo":                 undoLastChange,
+	"history":              showHistory,
+	"patch":                patchConfig,
+	"report":               reportConfig,
+	"stats":                showStats,
+	"toggle-provider":      toggleProvider,
+	"effective":            showEffectiveProviders,
+	"validate":             validateConfig,
+	"fetch-models":         fetchModels,
+	"cache":                cacheCommand,
+	"test-provider":        testProvider,
+	"test-all":             testAllProviders,
+	"move-model":           moveModel,
+	"wizard":               runWizard,
+	"init":                 initConfig,
+	"export":               exportConfig,
+	"rename-model":         renameModel,
+	"set-limit":            setModelLimit,
+	"prune-empty":          pruneEmpty,
+	"list-models":          listModels,
+	"which-default":        whichDefault,
+	"copy-provider-models": copyProviderModels,
+	"get":                  getConfigValue,
+	"doctor":               runDoctor,
+	"list-headers":         listHeaders,
+	"rename-header":        renameHeader,
+	"reset":                resetConfig,
+	"mcp-timeout":          setMCPTimeout,
+	"set-npm":              setProviderNPM,
+}
+
+func showHelp() {
+	detectProjectConfig()
+	fmt.Println("OpenCode Configuration Wizard")
+	fmt.Println()
+	fmt.Println("Usage: opencode-config-wizard [global flags] [command] [arguments]")
+	fmt.Println()
+	fmt.Println("Run without a command to start the interactive menu.")
+	fmt.Println()
+	fmt.Println("Global Flags:")
+	fmt.Println("  --compact           Save the config as minified single-line JSON")
+	fmt.Println("  --indent <n|tab>    Indent saved configs with n spaces or a tab (default 2)")
+	fmt.Println("  --no-color          Disable colored output (also honors NO_COLOR)")
+	fmt.Println("  --local             Use the project config (./opencode.json or")
+	fmt.Println("                      ./.opencode/opencode.json) instead of the global one;")
+	fmt.Println("                      an existing project config is used without it")
+	fmt.Println("  --global            Use the global config even if a project config exists")
+	fmt.Println("  --no-log            Don't record changes in the history log")
+	fmt.Println("  --json-errors       Print errors as {\"error\":...,\"code\":...} on stderr and, after")
+	fmt.Println("                      a command that saves the config, a JSON result on stdout")
+	fmt.Println("  --non-interactive   Fail instead of prompting, so missing flags are caught in")
+	fmt.Println("                      scripts and CI")
+	fmt.Println("  --yes               Save without confirming the summary at the end of add,")
+	fmt.Println("                      add-mcp and wizard")
+	fmt.Println("  --tui               Pick providers, models and MCP servers from lists with the")
+	fmt.Println("                      arrow keys (numbered prompts are used when not on a terminal)")
+	fmt.Println()
+	fmt.Println("Provider Commands:")
+	fmt.Println("  add                 Add a new provider")
+	fmt.Println("    --no-default-prompt")
+	fmt.Println("                      Leave the default and small model unchanged")
+	fmt.Println("    --npm <package>   AI SDK package to use instead of choosing from a menu")
+	fmt.Println("    --force           Replace an existing provider without asking (add-model")
+	fmt.Println("                      takes it too, for an existing model)")
+	fmt.Println("  add-model           Add a model to an existing provider")
+	fmt.Println("    --provider <key> --id <model> [--name <name>] [--context <n>] [--output <n>]")
+	fmt.Println("                      Add the model without prompting")
+	fmt.Println("  list [provider]     List configured providers, or a single provider")
+	fmt.Println("    --table           Show models in aligned columns")
+	fmt.Println("    --sort <order>    Order providers by name, key (default) or models")
+	fmt.Println("    --count           Print only the number of providers")
+	fmt.Println("    --merged          Show the project config layered over the global one")
+	fmt.Println("  list-models         Print every model as a provider/model reference")
+	fmt.Println("    --json            Print the references as a JSON array")
+	fmt.Println("    --sort <order>    Order models by id or context (smallest first)")
+	fmt.Println("  delete [provider]   Delete a provider")
+	fmt.Println("  delete-model [provider/model]")
+	fmt.Println("                      Delete a model from a provider")
+	fmt.Println("    --multi [provider]")
+	fmt.Println("                      Delete several models from one provider at once")
+	fmt.Println("  move-model [provider/model] [destination]")
+	fmt.Println("                      Move a model to another provider")
+	fmt.Println("  copy-provider-models [source] [destination]")
+	fmt.Println("                      Copy all of a provider's models onto another provider")
+	fmt.Println("  rename-model [provider/model] [new-id]")
+	fmt.Println("                      Change a model's ID, updating the default and small model")
+	fmt.Println("  set-limit [provider/model] [--context <n>] [--output <n>]")
+	fmt.Println("                      Change a model's token limits; unspecified ones are kept")
+	fmt.Println("  set-default [provider/model]")
+	fmt.Println("                      Set default model")
+	fmt.Println("  which-default       Show the default and small model and check they exist")
+	fmt.Println("    --json            Print them as a JSON object")
+	fmt.Println("  list-headers <provider>")
+	fmt.Println("                      Show a provider's custom headers")
+	fmt.Println("  rename-header <provider> <old> <new>")
+	fmt.Println("                      Rename a provider header, keeping its value")
+	fmt.Println("  set-option <provider> <key> <value>")
+	fmt.Println("                      Set a provider option (e.g., temperature)")
+	fmt.Println("    --string          Store the value as text instead of a number or boolean")
+	fmt.Println("  delete-option <provider> <key>")
+	fmt.Println("                      Remove a provider option")
+	fmt.Println("  set-npm [provider] [package]")
+	fmt.Println("                      Change a provider's SDK package")
+	fmt.Println("  toggle-provider <key>")
+	fmt.Println("                      Enable a disabled provider or disable an enabled one")
+	fmt.Println("  effective           Show which providers opencode will actually load")
+	fmt.Println("  purge-disabled      Delete configured providers listed in disabled_providers")
+	fmt.Println("  prune-empty         Delete providers with no models and empty disabled MCP servers")
+	fmt.Println("  fetch-models [provider]")
+	fmt.Println("                      List the provider's models and pick ones to add")
+	fmt.Println("    --refresh         Ignore the cached model list")
+	fmt.Println("  test-provider [provider]")
+	fmt.Println("                      Check that a provider's /models endpoint answers")
+	fmt.Println("  test-all            Check every provider at once and summarize the results")
+	fmt.Println("    --concurrency <n> Number of providers to test in parallel (default 4)")
+	fmt.Println("    --retries <n>     Retry failed checks with backoff (also for test-provider")
+	fmt.Println("                      and test-mcp); --timeout <duration> caps the total time")
+	fmt.Println("    --proxy <url>     Proxy to use instead of HTTP_PROXY/HTTPS_PROXY")
+	fmt.Println("  import-models <provider> <file>")
+	fmt.Println("                      Import models from a CSV or JSON file")
+	fmt.Println("    --overwrite       Replace models that already exist without asking")
+	fmt.Println("    --skip-existing   Keep models that already exist without asking")
+	fmt.Println()
+	fmt.Println("MCP Server Commands:")
+	fmt.Println("  add-mcp             Add a new MCP server (local or remote)")
+	fmt.Println("    --template <name> Pre-fill the server from a template")
+	fmt.Println("    --force           Replace an existing server without asking")
+	fmt.Println("  mcp-templates       List the built-in MCP server templates")
+	fmt.Println("  list-mcp            List all configured MCP servers")
+	fmt.Println("    --type <type>     Only show local or remote servers")
+	fmt.Println("    --enabled         Only show enabled servers")
+	fmt.Println("    --count           Print only the number of servers")
+	fmt.Println("  delete-mcp          Delete an MCP server")
+	fmt.Println("  mcp-env [name]      Edit a local MCP server's environment variables")
+	fmt.Println("    --from-file <file> Load variables from a .env file")
+	fmt.Println("  test-mcp [name]     Check that an MCP server is reachable")
+	fmt.Println("  mcp-oauth [name]    Edit a remote MCP server's OAuth settings")
+	fmt.Println("  mcp-timeout [name] [timeout]")
+	fmt.Println("                      Set an MCP server's timeout (e.g. 5000, 30s, 2m)")
+	fmt.Println("    --clear           Remove it so opencode uses its default")
+	fmt.Println("  clone-mcp <source> <new-name>")
+	fmt.Println("                      Copy an MCP server under a new name")
+	fmt.Println()
+	fmt.Println("Config Commands:")
+	fmt.Println("  init [--force]      Create a starter config with example entries")
+	fmt.Println("  reset [--force]     Back up the config and replace it with an empty one")
+	fmt.Println("  migrate             Upgrade legacy config fields to the current format")
+	fmt.Println("  validate            Check the config against the opencode schema")
+	fmt.Println("    --refresh-schema  Download the latest schema first (otherwise works offline)")
+	fmt.Println("    --check           For CI: print only problems, exit non-zero if there are any,")
+	fmt.Println("                      never prompt or write")
+	fmt.Println("  doctor              Check for dangling references and other common problems")
+	fmt.Println("    --fix             Repair the problems that have an unambiguous fix")
+	fmt.Println("  clone-config --out <file>")
+	fmt.Println("                      Write a portable copy without secrets or local providers")
+	fmt.Println("  import <file>       Replace the config with one from a file (backs up first)")
+	fmt.Println("    --stdin           Read the config from standard input instead")
+	fmt.Println("  export              Print the config to standard output")
+	fmt.Println("    --gzip            Compress the output (import reads compressed files as-is)")
+	fmt.Println("    --merged          Print the project config layered over the global one")
+	fmt.Println("  get <path>          Print the value at a JSON pointer, e.g. /provider/ollama")
+	fmt.Println("                      (strings unquoted, objects as indented JSON)")
+	fmt.Println("    --raw             Print objects as single-line JSON")
+	fmt.Println("    --json            Always print JSON, quoting strings too")
+	fmt.Println("  patch <file|->      Merge a JSON merge patch (RFC 7386) into the config")
+	fmt.Println("    --rfc6902         Apply a list of JSON patch (RFC 6902) operations instead")
+	fmt.Println("  undo                Restore the config from before the last change")
+	fmt.Println("  history             Show the log of changes made with the wizard")
+	fmt.Println("  stats [--json]      Summarize providers, models and MCP servers")
+	fmt.Println("  report [--out <file>]")
+	fmt.Println("                      Write a Markdown summary of the config with secrets redacted")
+	fmt.Println("  print-path          Print the path of the config in use")
+	fmt.Println("  cache clear         Delete cached model lists and schema")
+	fmt.Println("  profile <list|new|use|delete> [name]")
+	fmt.Println("                      Manage named config profiles")
+	fmt.Println()
+	fmt.Println("Exit Codes:")
+	fmt.Println("  0                   Success")
+	fmt.Println("  1                   Other failure (including usage errors)")
+	fmt.Println("  2                   Provider, model, MCP server, profile or file not found")
+	fmt.Println("  3                   Invalid input or config (parse or schema errors)")
+	fmt.Println("  4                   Reading or writing a file failed")
+	fmt.Println("  5                   Cancelled at a prompt")
+	fmt.Println()
+	fmt.Println("Other:")
+	fmt.Println("  wizard              Guided setup of a provider, models and an optional MCP server")
+	fmt.Println("  help                Show this help message")
+}
+
+func runCommand(name string, args []string) {
+	if name == "help" || name == "-h" || name == "--help" {
+		showHelp()
+		return
+	}
+
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n", name)
+		showHelp()
+		os.Exit(exitFailure)
+	}
+
+	detectProjectConfig()
+	currentCommand = name
+	if err := cmd(args); err != nil {
+		if jsonErrors {
+			printJSONError(err)
+		} else if errors.Is(err, errCancelled) {
+			fmt.Println("Cancelled")
+		} else {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		os.Exit(exitCode(err))
+	}
+
+	if jsonErrors {
+		printJSONResult(name)
+	}
+}
+
 func main() {
+	fs := flag.NewFlagSet("opencode-config-wizard", flag.ContinueOnError)
+	fs.BoolVar(&compactOutput, "compact", false, "write the config as minified JSON")
+	fs.StringVar(&indentOption, "indent", indentOption, "indentation for saved configs: a number of spaces or 'tab'")
+	fs.BoolVar(&noColor, "no-color", false, "disable colored output")
+	fs.BoolVar(&useLocalConfig, "local", false, "use the project config in the current directory")
+	fs.BoolVar(&useGlobalConfig, "global", false, "use the global config even if the current directory has a project config")
+	fs.BoolVar(&noLog, "no-log", false, "don't record changes in the history log")
+	fs.BoolVar(&jsonErrors, "json-errors", false, "report errors and results as JSON")
+	fs.BoolVar(&nonInteractive, "non-interactive", false, "fail instead of prompting for input")
+	fs.BoolVar(&useTUI, "tui", false, "pick from lists with the arrow keys")
+	fs.BoolVar(&assumeYes, "yes", false, "save without the confirmation at the end of add, add-mcp and wizard")
+	fs.Usage = showHelp
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		if err == flag.ErrHelp {
+			return
+		}
+		os.Exit(exitFailure)
+	}
+	if useLocalConfig && useGlobalConfig {
+		fmt.Fprintln(os.Stderr, "Error: --local and --global can't be used together")
+		os.Exit(exitFailure)
+	}
+
+	if fs.NArg() > 0 {
+		runCommand(fs.Arg(0), fs.Args()[1:])
+		return
+	}
+
+	detectProjectConfig()
 	fmt.Println("OpenCode Configuration Wizard")
 
 	for {
 		showMainMenu()
-		choice := getMenuChoice(2)
+		choice := getTopMenuChoice(2)
 
 		switch choice {
 		case 0:
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"regexp"
+	"testing"
+)
+
+// TestCommandsRegistered also keeps the main package compiling under
+// go test, so a command defined twice fails the build here.
+func TestCommandsRegistered(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	showHelp()
+	os.Stdout = stdout
+	w.Close()
+
+	var help bytes.Buffer
+	if _, err := io.Copy(&help, r); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, run := range commands {
+		if run == nil {
+			t.Errorf("command %q has no implementation", name)
+		}
+		if !regexp.MustCompile(`(?m)^\s+` + regexp.QuoteMeta(name) + `(\s|$)`).Match(help.Bytes()) {
+			t.Errorf("command %q is missing from help", name)
+		}
+	}
+}
@@ -1,20 +1,84 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+
+	ocfg "github.com/liamwilliams93/opencode-config-wizard/pkg/config"
 )
 
-func addMCPServer() error {
+// maxRecommendedTimeout is the MCP timeout in milliseconds above which a
+// warning is shown, since such values are almost always a typo.
+const maxRecommendedTimeout = 600000
+
+// parseTimeout parses an MCP timeout in milliseconds. A bare integer is taken
+// as milliseconds; anything else must be a Go duration such as "30s" or "2m".
+func parseTimeout(value string) (int, error) {
+	if ms, err := strconv.Atoi(value); err == nil {
+		if ms <= 0 {
+			return 0, fmt.Errorf("timeout must be positive")
+		}
+		return ms, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%w timeout '%s': use milliseconds or a duration like 30s", ocfg.ErrInvalid, value)
+	}
+	if d < time.Millisecond {
+		return 0, fmt.Errorf("timeout must be at least 1ms")
+	}
+	return int(d / time.Millisecond), nil
+}
+
+// promptTimeout reads a timeout, re-prompting until it parses. Blank input
+// returns 0.
+func promptTimeout(prompt string) int {
+	for {
+		input := promptString(prompt, "")
+		if input == "" {
+			return 0
+		}
+
+		timeout, err := parseTimeout(input)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		return timeout
+	}
+}
+
+func addMCPServer(args []string) error {
+	fs := flag.NewFlagSet("add-mcp", flag.ContinueOnError)
+	templateName := fs.String("template", "", "pre-fill the server from a template (see mcp-templates)")
+	force := fs.Bool("force", false, "replace an existing server without asking")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	var template *mcpTemplate
+	if *templateName != "" {
+		template = findMCPTemplate(*templateName)
+		if template == nil {
+			return fmt.Errorf("unknown MCP template '%s' (run mcp-templates to list them)", *templateName)
+		}
+	}
+
 	configPath, err := getConfigPath()
 	if err != nil {
 		return err
 	}
 
 	configDir := filepath.Dir(configPath)
-	if err := os.MkdirAll(configDir, 0755); err != nil {
+	if err := os.MkdirAll(configDir, 0700); err != nil {
 		return err
 	}
 
@@ -34,34 +98,50 @@ func addMCPServer() error {
 
 	fmt.Println("\n=== Add MCP Server ===")
 
-	serverName := promptString("Server name (e.g., my-mcp)", "")
+	defaultName := ""
+	if template != nil {
+		defaultName = template.name
+	}
+	serverName := promptString("Server name (e.g., my-mcp)", defaultName)
 	if serverName == "" {
-		fmt.Println("Cancelled")
-		return nil
+		return errCancelled
 	}
 
-	if _, exists := config.MCP[serverName]; exists {
+	if _, exists := config.MCP[serverName]; exists && !*force {
 		if !promptBool(fmt.Sprintf("Server '%s' already exists. Overwrite?", serverName), false) {
-			fmt.Println("Cancelled")
-			return nil
+			return errCancelled
 		}
 	}
 
-	fmt.Println("Server type:")
-	fmt.Println("  1. Local (runs a command)")
-	fmt.Println("  2. Remote (connects to a URL)")
+	if match := ocfg.CaseInsensitiveMatch(config.MCP, serverName); match != "" {
+		fmt.Printf("Warning: '%s' differs only in case from existing server '%s'\n", serverName, match)
+		if !*force && !promptBool("Continue anyway?", false) {
+			return errCancelled
+		}
+	}
 
-	typeSelection := promptString("Select type (1 or 2)", "1")
 	serverType := "local"
-	if typeSelection == "2" {
-		serverType = "remote"
+	if template == nil {
+		fmt.Println("Server type:")
+		fmt.Println("  1. Local (runs a command)")
+		fmt.Println("  2. Remote (connects to a URL)")
+
+		typeSelection := promptString("Select type (1 or 2)", "1")
+		if typeSelection == "2" {
+			serverType = "remote"
+		}
 	}
 
 	mcpServer := MCPServer{
 		Type: serverType,
 	}
 
-	if serverType == "local" {
+	if serverType == "local" && template != nil {
+		fmt.Printf("\n=== %s MCP Server ===\n", template.name)
+		fmt.Println(template.description)
+
+		mcpServer.Command = promptTemplateCommand(*template)
+	} else if serverType == "local" {
 		fmt.Println("\n=== Local MCP Server ===")
 
 		command := promptString("Command (e.g., npx, bun)", "npx")
@@ -83,15 +163,27 @@ func addMCPServer() error {
 		}
 
 		mcpServer.Command = cmdArray
+	}
 
+	if serverType == "local" {
 		if promptBool("Add environment variables?", false) {
 			envVars := make(map[string]string)
+			if envFile := promptString("Load from .env file (leave blank to enter manually)", ""); envFile != "" {
+				loaded, err := parseEnvFile(envFile)
+				if err != nil {
+					return err
+				}
+				for k, v := range loaded {
+					envVars[k] = v
+				}
+				fmt.Printf("Loaded %d variable(s) from %s\n", len(loaded), envFile)
+			}
 			for {
-				envName := promptString("Environment variable name (leave blank to finish)", "")
+				envName := promptEnvName("Environment variable name (leave blank to finish)")
 				if envName == "" {
 					break
 				}
-				envValue := promptString("Environment variable value", "")
+				envValue := promptString("Environment variable value (use ${VAR} to reference a secret)", "")
 				if envValue != "" {
 					envVars[envName] = envValue
 				}
@@ -115,11 +207,11 @@ func addMCPServer() error {
 		if promptBool("Add custom headers?", false) {
 			headers := make(map[string]string)
 			for {
-				headerName := promptString("Header name (leave blank to finish)", "")
+				headerName := promptHeaderName("Header name (leave blank to finish)")
 				if headerName == "" {
 					break
 				}
-				headerValue := promptString("Header value", "")
+				headerValue := promptHeaderValue("Header value (use ${VAR} to reference a secret)")
 				if headerValue != "" {
 					headers[headerName] = headerValue
 				}
@@ -158,15 +250,24 @@ func addMCPServer() error {
 	}
 
 	if promptBool("Set custom timeout?", false) {
-		timeoutStr := promptString("Timeout in milliseconds (default: 5000)", "")
-		if timeoutStr != "" {
-			var timeout int
-			fmt.Sscanf(timeoutStr, "%d", &timeout)
+		timeout := promptTimeout("Timeout (e.g., 5000, 30s, 2m; default: 5000ms)")
+		if timeout > 0 {
+			if timeout > maxRecommendedTimeout {
+				fmt.Printf("Warning: %d ms is over %d minutes\n", timeout, maxRecommendedTimeout/60000)
+			}
 			mcpServer.Timeout = &timeout
 		}
 	}
 
-	config.MCP[serverName] = mcpServer
+	fmt.Println("\n=== Summary ===")
+	printMCPServer(serverName, mcpServer)
+	if !confirmSave() {
+		return errCancelled
+	}
+
+	if err := config.AddMCPServer(serverName, mcpServer, true); err != nil {
+		return err
+	}
 
 	if err := saveConfig(config, configPath); err != nil {
 		return err
@@ -182,7 +283,56 @@ func addMCPServer() error {
 	return nil
 }
 
-func listMCPServers() error {
+// printMCPServer prints a server's settings as list-mcp shows them.
+func printMCPServer(name string, server MCPServer) {
+	fmt.Printf("\nServer: %s\n", name)
+	fmt.Printf("  Type: %s\n", server.Type)
+
+	fmt.Printf("  Status: %s\n", statusText(server.Enabled == nil || *server.Enabled))
+
+	if server.Type == "local" {
+		if len(server.Command) > 0 {
+			fmt.Printf("  Command: %v\n", server.Command)
+		}
+		if len(server.Environment) > 0 {
+			fmt.Println("  Environment variables:")
+			for k, v := range server.Environment {
+				fmt.Printf("    %s: %s\n", k, v)
+			}
+		}
+	} else {
+		if server.URL != "" {
+			fmt.Printf("  URL: %s\n", server.URL)
+		}
+		if len(server.Headers) > 0 {
+			fmt.Println("  Headers:")
+			for k, v := range server.Headers {
+				fmt.Printf("    %s: %s\n", k, v)
+			}
+		}
+		if len(server.OAuth) > 0 {
+			fmt.Println("  OAuth configured")
+		}
+	}
+
+	if server.Timeout != nil {
+		fmt.Printf("  Timeout: %d ms\n", *server.Timeout)
+	}
+}
+
+func listMCPServers(args []string) error {
+	fs := flag.NewFlagSet("list-mcp", flag.ContinueOnError)
+	typeFilter := fs.String("type", "", "only show servers of this type (local or remote)")
+	enabledOnly := fs.Bool("enabled", false, "only show servers that are enabled")
+	countOnly := fs.Bool("count", false, "print only the number of servers")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if *typeFilter != "" && *typeFilter != "local" && *typeFilter != "remote" {
+		return fmt.Errorf("%w type '%s': must be 'local' or 'remote'", ocfg.ErrInvalid, *typeFilter)
+	}
+
 	configPath, err := getConfigPath()
 	if err != nil {
 		return err
@@ -193,55 +343,61 @@ func listMCPServers() error {
 		return err
 	}
 
-	if len(config.MCP) == 0 {
-		fmt.Println("No MCP servers configured")
-		return nil
+	matches := func(server MCPServer) bool {
+		if *typeFilter != "" && server.Type != *typeFilter {
+			return false
+		}
+		return !*enabledOnly || server.Enabled == nil || *server.Enabled
 	}
 
-	fmt.Println("\n=== Configured MCP Servers ===")
-	for name, server := range config.MCP {
-		fmt.Printf("\nServer: %s\n", name)
-		fmt.Printf("  Type: %s\n", server.Type)
-
-		status := "disabled"
-		if server.Enabled == nil || *server.Enabled {
-			status = "enabled"
+	count := 0
+	for _, server := range config.MCP {
+		if matches(server) {
+			count++
 		}
-		fmt.Printf("  Status: %s\n", status)
+	}
 
-		if server.Type == "local" {
-			if len(server.Command) > 0 {
-				fmt.Printf("  Command: %v\n", server.Command)
-			}
-			if len(server.Environment) > 0 {
-				fmt.Println("  Environment variables:")
-				for k, v := range server.Environment {
-					fmt.Printf("    %s: %s\n", k, v)
-				}
-			}
+	if *countOnly {
+		fmt.Println(count)
+		return nil
+	}
+
+	// kind describes the servers shown, e.g. "enabled local".
+	var kind []string
+	if *enabledOnly {
+		kind = append(kind, "enabled")
+	}
+	if *typeFilter != "" {
+		kind = append(kind, *typeFilter)
+	}
+
+	if count == 0 {
+		if len(kind) > 0 {
+			fmt.Printf("No %s MCP servers configured\n", strings.Join(kind, " "))
 		} else {
-			if server.URL != "" {
-				fmt.Printf("  URL: %s\n", server.URL)
-			}
-			if len(server.Headers) > 0 {
-				fmt.Println("  Headers:")
-				for k, v := range server.Headers {
-					fmt.Printf("    %s: %s\n", k, v)
-				}
-			}
-			if len(server.OAuth) > 0 {
-				fmt.Println("  OAuth configured")
-			}
+			fmt.Println("No MCP servers configured")
 		}
+		return nil
+	}
 
-		if server.Timeout != nil {
-			fmt.Printf("  Timeout: %d ms\n", *server.Timeout)
+	fmt.Println("\n=== Configured MCP Servers ===")
+	for name, server := range config.MCP {
+		if !matches(server) {
+			continue
 		}
+
+		printMCPServer(name, server)
+	}
+
+	if len(kind) > 0 {
+		fmt.Printf("\nTotal: %d %s server(s)\n", count, strings.Join(kind, " "))
+	} else {
+		fmt.Printf("\nTotal: %d server(s)\n", count)
 	}
 	return nil
 }
 
-func deleteMCPServer() error {
+func deleteMCPServer(args []string) error {
 	configPath, err := getConfigPath()
 	if err != nil {
 		return err
@@ -258,43 +414,659 @@ func deleteMCPServer() error {
 	}
 
 	fmt.Println("\n=== Delete MCP Server ===")
-	fmt.Println("Available servers:")
 
-	keys := make([]string, 0, len(config.MCP))
-	i := 1
-	for name, server := range config.MCP {
+	keys := sortedMCPNames(config)
+	labels := make([]string, len(keys))
+	for i, name := range keys {
+		server := config.MCP[name]
 		enabledStr := "disabled"
 		if server.Enabled == nil || *server.Enabled {
 			enabledStr = "enabled"
 		}
-		fmt.Printf("  %d. %s (%s) - %s\n", i, name, server.Type, enabledStr)
-		keys = append(keys, name)
-		i++
+		labels[i] = fmt.Sprintf("%s (%s) - %s", name, server.Type, enabledStr)
+	}
+
+	nameToDelete, ok := tuiSelect("MCP server to delete", keys, labels)
+	if !ok {
+		fmt.Println("Available servers:")
+		for i, label := range labels {
+			fmt.Printf("  %d. %s\n", i+1, label)
+		}
+
+		choice := getMenuChoice(len(keys))
+		if choice == -1 {
+			fmt.Println("Invalid choice")
+			return nil
+		}
+		if choice > 0 {
+			nameToDelete = keys[choice-1]
+		}
+	}
+	if nameToDelete == "" {
+		return errCancelled
+	}
+
+	if !promptBool(fmt.Sprintf("Are you sure you want to delete MCP server '%s'?", nameToDelete), false) {
+		return errCancelled
+	}
+
+	removed := config.MCP[nameToDelete]
+	if err := config.DeleteMCPServer(nameToDelete); err != nil {
+		return err
+	}
+
+	if err := saveConfig(config, configPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("Deleted MCP server: %s\n", nameToDelete)
+	fmt.Printf("  Type: %s\n", removed.Type)
+	if removed.Type == "local" {
+		fmt.Printf("  Command: %s\n", strings.Join(removed.Command, " "))
+		if len(removed.Environment) > 0 {
+			fmt.Printf("  Environment: %d variable(s)\n", len(removed.Environment))
+		}
+	} else {
+		fmt.Printf("  URL: %s\n", removed.URL)
+		if len(removed.Headers) > 0 {
+			fmt.Printf("  Headers: %d\n", len(removed.Headers))
+		}
+	}
+	return nil
+}
+
+// sortedMCPNames returns the names of the configured MCP servers, sorted.
+func sortedMCPNames(config *Config) []string {
+	names := make([]string, 0, len(config.MCP))
+	for name := range config.MCP {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// promptMCPServerName lists the configured MCP servers and asks the user to
+// pick one by number or name. It returns "" if the user cancelled.
+func promptMCPServerName(config *Config) string {
+	names := sortedMCPNames(config)
+	labels := make([]string, len(names))
+	for i, name := range names {
+		labels[i] = fmt.Sprintf("%s (%s)", name, config.MCP[name].Type)
+	}
+
+	if name, ok := tuiSelect("Select a server", names, labels); ok {
+		return name
+	}
+
+	fmt.Println("Available servers:")
+	for i, label := range labels {
+		fmt.Printf("  %d. %s\n", i+1, label)
+	}
+
+	selection := promptString("Enter server number or name", "")
+	if selection == "" {
+		return ""
+	}
+	return resolveSelection(selection, names)
+}
+
+// envNamePattern matches names that can be passed to a process as an
+// environment variable.
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// promptEnvName asks for an environment variable name, asking again until it
+// is valid. Blank input returns "".
+func promptEnvName(prompt string) string {
+	for {
+		name := promptString(prompt, "")
+		if name == "" || envNamePattern.MatchString(name) {
+			return name
+		}
+		fmt.Printf("Invalid variable name '%s': use letters, digits and underscores, not starting with a digit\n", name)
+	}
+}
+
+// dotenvEscapes expands the escapes dotenv allows in double-quoted values.
+// Any other backslash is kept as it is.
+var dotenvEscapes = strings.NewReplacer(`\n`, "\n", `\"`, `"`, `\\`, `\`)
+
+// parseEnvFile reads KEY=value pairs from a .env file. Blank lines and lines
+// starting with # are skipped, an optional "export " prefix is allowed, and
+// values may be wrapped in single or double quotes.
+func parseEnvFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseEnv(path, data)
+}
+
+// parseEnv parses the contents of a .env file; path is only used in errors.
+// Quoting follows dotenv: single-quoted values are taken literally, and in
+// double-quoted values only \n, \" and \\ are escapes, so Windows paths and
+// regular expressions keep their backslashes.
+func parseEnv(path string, data []byte) (map[string]string, error) {
+	env := make(map[string]string)
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected KEY=value", path, i+1)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		if !envNamePattern.MatchString(key) {
+			return nil, fmt.Errorf("%s:%d: %w variable name '%s'", path, i+1, ocfg.ErrInvalid, key)
+		}
+
+		if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+			value = dotenvEscapes.Replace(value[1 : len(value)-1])
+		} else if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+			value = value[1 : len(value)-1]
+		} else if j := strings.Index(value, " #"); j >= 0 {
+			value = strings.TrimSpace(value[:j])
+		}
+
+		env[key] = value
+	}
+	return env, nil
+}
+
+func editMCPEnvironment(args []string) error {
+	fs := flag.NewFlagSet("mcp-env", flag.ContinueOnError)
+	fromFile := fs.String("from-file", "", "load variables from a .env file")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("usage: mcp-env [name] [--from-file .env]")
+	}
+
+	configPath, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	var serverName string
+	if len(args) == 1 {
+		serverName = args[0]
+		if _, exists := config.MCP[serverName]; !exists {
+			return fmt.Errorf("MCP server '%s' %w", serverName, ocfg.ErrNotFound)
+		}
+	} else {
+		if len(config.MCP) == 0 {
+			fmt.Println("No MCP servers configured")
+			return nil
+		}
+
+		fmt.Println("\n=== Edit MCP Environment ===")
+
+		serverName = promptMCPServerName(config)
+		if serverName == "" {
+			return errCancelled
+		}
+		if _, exists := config.MCP[serverName]; !exists {
+			return fmt.Errorf("MCP server '%s' %w", serverName, ocfg.ErrNotFound)
+		}
 	}
 
-	choice := getMenuChoice(len(keys))
-	if choice == -1 {
-		fmt.Println("Invalid choice")
+	server := config.MCP[serverName]
+	if server.Type != "local" {
+		return fmt.Errorf("MCP server '%s' is %s; environment variables only apply to local servers", serverName, server.Type)
+	}
+	if server.Environment == nil {
+		server.Environment = make(map[string]string)
+	}
+
+	if *fromFile != "" {
+		loaded, err := parseEnvFile(*fromFile)
+		if err != nil {
+			return err
+		}
+		for k, v := range loaded {
+			server.Environment[k] = v
+		}
+		config.MCP[serverName] = server
+
+		if err := saveConfig(config, configPath); err != nil {
+			return err
+		}
+
+		fmt.Printf("Loaded %d variable(s) from %s into MCP server: %s\n", len(loaded), *fromFile, serverName)
 		return nil
 	}
-	if choice == 0 {
-		fmt.Println("Cancelled")
+
+	changed := false
+	for {
+		fmt.Printf("\nEnvironment for %s:\n", serverName)
+		keys := make([]string, 0, len(server.Environment))
+		for k := range server.Environment {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		if len(keys) == 0 {
+			fmt.Println("  (none)")
+		}
+		for _, k := range keys {
+			fmt.Printf("  %s=%s\n", k, server.Environment[k])
+		}
+
+		fmt.Println()
+		fmt.Println("1. Add or change a variable")
+		fmt.Println("2. Delete a variable")
+		fmt.Println("0. Done")
+
+		choice := getMenuChoice(2)
+		switch choice {
+		case 0:
+		case 1:
+			name := promptEnvName("Variable name")
+			if name == "" {
+				continue
+			}
+			value := promptString("Variable value", server.Environment[name])
+			if value == "" {
+				continue
+			}
+			server.Environment[name] = value
+			changed = true
+			continue
+		case 2:
+			name := promptString("Variable name to delete", "")
+			if _, exists := server.Environment[name]; !exists {
+				if name != "" {
+					fmt.Printf("Variable '%s' not set\n", name)
+				}
+				continue
+			}
+			delete(server.Environment, name)
+			changed = true
+			continue
+		default:
+			fmt.Println("Invalid choice, please try again")
+			continue
+		}
+		break
+	}
+
+	if !changed {
+		fmt.Println("No changes made")
 		return nil
 	}
 
-	nameToDelete := keys[choice-1]
+	if len(server.Environment) == 0 {
+		server.Environment = nil
+	}
+	config.MCP[serverName] = server
 
-	if !promptBool(fmt.Sprintf("Are you sure you want to delete MCP server '%s'?", nameToDelete), false) {
-		fmt.Println("Cancelled")
+	if err := saveConfig(config, configPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("Updated environment for MCP server: %s\n", serverName)
+	return nil
+}
+
+func editMCPOAuth(args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("usage: mcp-oauth [name]")
+	}
+
+	configPath, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	var serverName string
+	if len(args) == 1 {
+		serverName = args[0]
+		if _, exists := config.MCP[serverName]; !exists {
+			return fmt.Errorf("MCP server '%s' %w", serverName, ocfg.ErrNotFound)
+		}
+	} else {
+		if len(config.MCP) == 0 {
+			fmt.Println("No MCP servers configured")
+			return nil
+		}
+
+		fmt.Println("\n=== Edit MCP OAuth ===")
+
+		serverName = promptMCPServerName(config)
+		if serverName == "" {
+			return errCancelled
+		}
+		if _, exists := config.MCP[serverName]; !exists {
+			return fmt.Errorf("MCP server '%s' %w", serverName, ocfg.ErrNotFound)
+		}
+	}
+
+	server := config.MCP[serverName]
+	if server.Type != "remote" {
+		return fmt.Errorf("MCP server '%s' is %s; OAuth only applies to remote servers", serverName, server.Type)
+	}
+
+	oauthConfig := make(map[string]interface{})
+	for k, v := range server.OAuth {
+		oauthConfig[k] = v
+	}
+
+	// setOAuthField stores value under key, removing the key when blank.
+	setOAuthField := func(key, value string) {
+		if value == "" {
+			delete(oauthConfig, key)
+		} else {
+			oauthConfig[key] = value
+		}
+	}
+	currentField := func(key string) string {
+		value, _ := oauthConfig[key].(string)
+		return value
+	}
+
+	changed := false
+	for {
+		fmt.Printf("\nOAuth for %s:\n", serverName)
+		if len(oauthConfig) == 0 {
+			fmt.Println("  (not configured)")
+		}
+		if clientId := currentField("clientId"); clientId != "" {
+			fmt.Printf("  Client ID: %s\n", clientId)
+		}
+		if _, ok := oauthConfig["clientSecret"]; ok {
+			fmt.Println("  Client Secret: ********")
+		}
+		if scope := currentField("scope"); scope != "" {
+			fmt.Printf("  Scope: %s\n", scope)
+		}
+
+		fmt.Println()
+		fmt.Println("1. Set client ID")
+		fmt.Println("2. Set client secret")
+		fmt.Println("3. Set scopes")
+		fmt.Println("4. Clear OAuth configuration")
+		fmt.Println("0. Done")
+
+		choice := getMenuChoice(4)
+		switch choice {
+		case 0:
+		case 1:
+			setOAuthField("clientId", promptString("Client ID (leave blank to remove)", ""))
+			changed = true
+			continue
+		case 2:
+			setOAuthField("clientSecret", promptString("Client Secret (leave blank to remove)", ""))
+			changed = true
+			continue
+		case 3:
+			setOAuthField("scope", promptString("OAuth scopes (leave blank to remove)", ""))
+			changed = true
+			continue
+		case 4:
+			if promptBool("Are you sure you want to clear the OAuth configuration?", false) {
+				oauthConfig = make(map[string]interface{})
+				changed = true
+			}
+			continue
+		default:
+			fmt.Println("Invalid choice, please try again")
+			continue
+		}
+		break
+	}
+
+	if !changed {
+		fmt.Println("No changes made")
 		return nil
 	}
 
-	delete(config.MCP, nameToDelete)
+	server.OAuth = nil
+	if len(oauthConfig) > 0 {
+		server.OAuth = oauthConfig
+	}
+	config.MCP[serverName] = server
 
 	if err := saveConfig(config, configPath); err != nil {
 		return err
 	}
 
-	fmt.Printf("Deleted MCP server: %s\n", nameToDelete)
+	if server.OAuth == nil {
+		fmt.Printf("Cleared OAuth for MCP server: %s\n", serverName)
+	} else {
+		fmt.Printf("Updated OAuth for MCP server: %s\n", serverName)
+	}
+	return nil
+}
+
+// cloneMCPServer returns a deep copy of server so that edits to the copy never
+// affect the original's slices, maps or pointers.
+func cloneMCPServer(server MCPServer) MCPServer {
+	clone := server
+
+	if server.Command != nil {
+		clone.Command = append([]string(nil), server.Command...)
+	}
+	if server.Environment != nil {
+		clone.Environment = make(map[string]string, len(server.Environment))
+		for k, v := range server.Environment {
+			clone.Environment[k] = v
+		}
+	}
+	if server.Headers != nil {
+		clone.Headers = make(map[string]string, len(server.Headers))
+		for k, v := range server.Headers {
+			clone.Headers[k] = v
+		}
+	}
+	if server.OAuth != nil {
+		clone.OAuth = make(map[string]interface{}, len(server.OAuth))
+		for k, v := range server.OAuth {
+			clone.OAuth[k] = v
+		}
+	}
+	if server.Enabled != nil {
+		enabled := *server.Enabled
+		clone.Enabled = &enabled
+	}
+	if server.Timeout != nil {
+		timeout := *server.Timeout
+		clone.Timeout = &timeout
+	}
+	return clone
+}
+
+func cloneMCP(args []string) error {
+	if len(args) != 0 && len(args) != 2 {
+		return fmt.Errorf("usage: clone-mcp <source> <new-name>")
+	}
+
+	configPath, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	var sourceName, newName string
+	if len(args) == 2 {
+		sourceName, newName = args[0], args[1]
+		if _, exists := config.MCP[sourceName]; !exists {
+			return fmt.Errorf("MCP server '%s' %w", sourceName, ocfg.ErrNotFound)
+		}
+	} else {
+		if len(config.MCP) == 0 {
+			fmt.Println("No MCP servers configured")
+			return nil
+		}
+
+		fmt.Println("\n=== Clone MCP Server ===")
+
+		sourceName = promptMCPServerName(config)
+		if sourceName == "" {
+			return errCancelled
+		}
+		if _, exists := config.MCP[sourceName]; !exists {
+			return fmt.Errorf("MCP server '%s' %w", sourceName, ocfg.ErrNotFound)
+		}
+
+		newName = promptString("New server name", "")
+		if newName == "" {
+			return errCancelled
+		}
+	}
+
+	if _, exists := config.MCP[newName]; exists {
+		if !promptBool(fmt.Sprintf("Server '%s' already exists. Overwrite?", newName), false) {
+			return errCancelled
+		}
+	}
+
+	if match := ocfg.CaseInsensitiveMatch(config.MCP, newName); match != "" {
+		fmt.Printf("Warning: '%s' differs only in case from existing server '%s'\n", newName, match)
+		if !promptBool("Continue anyway?", false) {
+			return errCancelled
+		}
+	}
+
+	clone := cloneMCPServer(config.MCP[sourceName])
+
+	if promptBool("Change a field on the clone?", false) {
+		if clone.Type == "local" {
+			fmt.Println("1. Command")
+		} else {
+			fmt.Println("1. URL")
+		}
+		fmt.Println("2. Enabled on startup")
+		fmt.Println("3. Timeout")
+
+		switch getMenuChoice(3) {
+		case 1:
+			if clone.Type == "local" {
+				command := promptString("Command", strings.Join(clone.Command, " "))
+				if fields := strings.Fields(command); len(fields) > 0 {
+					clone.Command = fields
+				}
+			} else {
+				clone.URL = promptString("Server URL", clone.URL)
+			}
+		case 2:
+			enabled := promptBool("Enable server on startup?", clone.Enabled == nil || *clone.Enabled)
+			clone.Enabled = nil
+			if !enabled {
+				clone.Enabled = &enabled
+			}
+		case 3:
+			if timeout := promptTimeout("Timeout (e.g., 5000, 30s, 2m; blank to clear)"); timeout > 0 {
+				clone.Timeout = &timeout
+			} else {
+				clone.Timeout = nil
+			}
+		}
+	}
+
+	config.MCP[newName] = clone
+
+	if err := saveConfig(config, configPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("Cloned MCP server '%s' to '%s'\n", sourceName, newName)
+	return nil
+}
+
+func setMCPTimeout(args []string) error {
+	fs := flag.NewFlagSet("mcp-timeout", flag.ContinueOnError)
+	clearTimeout := fs.Bool("clear", false, "remove the timeout so opencode uses its default")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 2 || (*clearTimeout && len(positional) > 1) {
+		return fmt.Errorf("usage: mcp-timeout [name] [timeout] | mcp-timeout [name] --clear")
+	}
+
+	configPath, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	var serverName string
+	if len(positional) > 0 {
+		serverName = positional[0]
+	} else {
+		if len(config.MCP) == 0 {
+			fmt.Println("No MCP servers configured")
+			return nil
+		}
+
+		fmt.Println("\n=== Set MCP Timeout ===")
+
+		serverName = promptMCPServerName(config)
+		if serverName == "" {
+			return errCancelled
+		}
+	}
+	server, exists := config.MCP[serverName]
+	if !exists {
+		return fmt.Errorf("MCP server '%s' %w", serverName, ocfg.ErrNotFound)
+	}
+
+	var timeout int
+	switch {
+	case *clearTimeout:
+	case len(positional) == 2:
+		if timeout, err = parseTimeout(positional[1]); err != nil {
+			return err
+		}
+	default:
+		current := "opencode's default"
+		if server.Timeout != nil {
+			current = fmt.Sprintf("%d ms", *server.Timeout)
+		}
+		fmt.Printf("Current timeout: %s\n", current)
+		timeout = promptTimeout("Timeout (e.g., 5000, 30s, 2m; blank to clear)")
+	}
+
+	if timeout > maxRecommendedTimeout {
+		fmt.Printf("Warning: %d ms is over %d minutes\n", timeout, maxRecommendedTimeout/60000)
+	}
+	server.Timeout = nil
+	if timeout > 0 {
+		server.Timeout = &timeout
+	}
+	config.MCP[serverName] = server
+
+	if err := saveConfig(config, configPath); err != nil {
+		return err
+	}
+
+	if server.Timeout == nil {
+		fmt.Printf("Cleared the timeout for '%s'; opencode will use its default\n", serverName)
+	} else {
+		fmt.Printf("Timeout for '%s' set to %d ms\n", serverName, timeout)
+	}
 	return nil
 }
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	ocfg "github.com/liamwilliams93/opencode-config-wizard/pkg/config"
+)
+
+func TestParseEnv(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		input string
+		want  map[string]string
+	}{
+		{"plain", "API_KEY=abc123\n", map[string]string{"API_KEY": "abc123"}},
+		{"comments and blanks", "# comment\n\nA=1 # trailing\r\n", map[string]string{"A": "1"}},
+		{"export prefix", "export TOKEN=xyz\n", map[string]string{"TOKEN": "xyz"}},
+		{"windows path", `HOME_DIR="C:\Users\me"`, map[string]string{"HOME_DIR": `C:\Users\me`}},
+		{"regular expression", `PATTERN="\d+"`, map[string]string{"PATTERN": `\d+`}},
+		{"double-quoted escapes", `MSG="line1\nsay \"hi\" \\n"`, map[string]string{"MSG": "line1\nsay \"hi\" \\n"}},
+		{"single quotes are literal", `RAW='a\nb "c"'`, map[string]string{"RAW": `a\nb "c"`}},
+		{"quoted hash", `URL="http://x/#frag"`, map[string]string{"URL": "http://x/#frag"}},
+		{"empty value", "EMPTY=\n", map[string]string{"EMPTY": ""}},
+	} {
+		got, err := parseEnv(".env", []byte(tc.input))
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestParseEnvErrors(t *testing.T) {
+	for _, input := range []string{"NOEQUALS\n", "1BAD=x\n", "BAD-NAME=x\n"} {
+		if _, err := parseEnv(".env", []byte(input)); err == nil {
+			t.Errorf("%q: no error", input)
+		}
+	}
+	if _, err := parseEnv(".env", []byte("1BAD=x\n")); !errors.Is(err, ocfg.ErrInvalid) {
+		t.Errorf("invalid name: error %v, want ErrInvalid", err)
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+type mcpTemplate struct {
+	name        string
+	description string
+	command     []string
+	params      []mcpTemplateParam
+}
+
+// mcpTemplateParam is a value the user is asked for when adding a server from
+// a template. It is appended to the command, preceded by flag if set.
+type mcpTemplateParam struct {
+	prompt       string
+	flag         string
+	defaultValue string
+}
+
+// mcpTemplates is the catalog of common MCP servers offered by
+// add-mcp --template.
+var mcpTemplates = []mcpTemplate{
+	{
+		name:        "filesystem",
+		description: "Read and write files under an allowed directory",
+		command:     []string{"npx", "-y", "@modelcontextprotocol/server-filesystem"},
+		params:      []mcpTemplateParam{{prompt: "Allowed directory", defaultValue: "."}},
+	},
+	{
+		name:        "git",
+		description: "Inspect and operate on a local git repository",
+		command:     []string{"uvx", "mcp-server-git"},
+		params:      []mcpTemplateParam{{prompt: "Repository path", flag: "--repository", defaultValue: "."}},
+	},
+	{
+		name:        "fetch",
+		description: "Fetch web pages and convert them to markdown",
+		command:     []string{"uvx", "mcp-server-fetch"},
+	},
+	{
+		name:        "sqlite",
+		description: "Query and modify a SQLite database",
+		command:     []string{"uvx", "mcp-server-sqlite"},
+		params:      []mcpTemplateParam{{prompt: "Database path", flag: "--db-path", defaultValue: "./data.db"}},
+	},
+	{
+		name:        "puppeteer",
+		description: "Browser automation with Puppeteer",
+		command:     []string{"npx", "-y", "@modelcontextprotocol/server-puppeteer"},
+	},
+}
+
+func findMCPTemplate(name string) *mcpTemplate {
+	for i := range mcpTemplates {
+		if mcpTemplates[i].name == name {
+			return &mcpTemplates[i]
+		}
+	}
+	return nil
+}
+
+// promptTemplateCommand builds the command for template, asking for each of
+// its parameters.
+func promptTemplateCommand(template mcpTemplate) []string {
+	command := append([]string(nil), template.command...)
+	for _, param := range template.params {
+		value := promptString(param.prompt, param.defaultValue)
+		if value == "" {
+			continue
+		}
+		if param.flag != "" {
+			command = append(command, param.flag)
+		}
+		command = append(command, value)
+	}
+	return command
+}
+
+func listMCPTemplates(args []string) error {
+	fmt.Println("\n=== MCP Server Templates ===")
+	for _, template := range mcpTemplates {
+		command := strings.Join(template.command, " ")
+		for _, param := range template.params {
+			if param.flag != "" {
+				command += " " + param.flag
+			}
+			command += fmt.Sprintf(" <%s>", strings.ToLower(param.prompt))
+		}
+		fmt.Printf("\n%s\n", template.name)
+		fmt.Printf("  %s\n", template.description)
+		fmt.Printf("  Command: %s\n", command)
+	}
+	fmt.Println("\nUse: opencode-config-wizard add-mcp --template <name>")
+	return nil
+}
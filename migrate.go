@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	ocfg "github.com/liamwilliams93/opencode-config-wizard/pkg/config"
+)
+
+// migrateRawConfig rewrites legacy config shapes in raw in place and returns a
+// description of each change made.
+func migrateRawConfig(raw map[string]interface{}) []string {
+	var changes []string
+
+	if providers, ok := raw["provider"].(map[string]interface{}); ok {
+		for _, key := range sortedKeys(providers) {
+			provider, ok := providers[key].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			changes = append(changes, migrateProviderModels(key, provider)...)
+		}
+	}
+
+	if servers, ok := raw["mcp"].(map[string]interface{}); ok {
+		for _, name := range sortedKeys(servers) {
+			server, ok := servers[name].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			changes = append(changes, migrateMCPServer(name, server)...)
+		}
+	}
+
+	return changes
+}
+
+// migrateProviderModels converts a flat "models" array into the map keyed by
+// model ID that opencode now expects.
+func migrateProviderModels(key string, provider map[string]interface{}) []string {
+	list, ok := provider["models"].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	models := make(map[string]interface{})
+	for _, item := range list {
+		switch model := item.(type) {
+		case string:
+			models[model] = map[string]interface{}{"name": model}
+		case map[string]interface{}:
+			id, _ := model["id"].(string)
+			if id == "" {
+				id, _ = model["name"].(string)
+			}
+			if id == "" {
+				continue
+			}
+			delete(model, "id")
+			if _, ok := model["name"]; !ok {
+				model["name"] = id
+			}
+			models[id] = model
+		}
+	}
+
+	provider["models"] = models
+	return []string{fmt.Sprintf("provider '%s': converted models array to a map of %d model(s)", key, len(models))}
+}
+
+// migrateMCPServer rewrites old MCP server fields: type aliases, a string
+// command with separate args, "env" instead of "environment", and "disabled"
+// instead of "enabled".
+func migrateMCPServer(name string, server map[string]interface{}) []string {
+	var changes []string
+
+	if serverType, ok := server["type"].(string); ok {
+		if canonical, ok := ocfg.MCPTypeAliases[serverType]; ok {
+			server["type"] = canonical
+			changes = append(changes, fmt.Sprintf("mcp '%s': changed type '%s' to '%s'", name, serverType, canonical))
+		}
+	}
+
+	if command, ok := server["command"].(string); ok {
+		cmdArray := []interface{}{command}
+		if args, ok := server["args"].([]interface{}); ok {
+			cmdArray = append(cmdArray, args...)
+		}
+		delete(server, "args")
+		server["command"] = cmdArray
+		if _, ok := server["type"]; !ok {
+			server["type"] = "local"
+		}
+		changes = append(changes, fmt.Sprintf("mcp '%s': merged command and args into a command array", name))
+	}
+
+	if env, ok := server["env"]; ok {
+		if _, exists := server["environment"]; !exists {
+			server["environment"] = env
+		}
+		delete(server, "env")
+		changes = append(changes, fmt.Sprintf("mcp '%s': renamed env to environment", name))
+	}
+
+	if disabled, ok := server["disabled"].(bool); ok {
+		delete(server, "disabled")
+		if disabled {
+			server["enabled"] = false
+		}
+		changes = append(changes, fmt.Sprintf("mcp '%s': replaced disabled with enabled", name))
+	}
+
+	return changes
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func migrateConfig(args []string) error {
+	configPath, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			fmt.Println("No config file to migrate")
+			return nil
+		}
+		return err
+	}
+
+	// Read the file the way loading does, so a gzipped config or one with a
+	// byte order mark or trailing commas can be migrated too.
+	data, warnings, err := ocfg.Normalize(data)
+	if err != nil {
+		return err
+	}
+	printConfigWarnings(warnings)
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("%w config: %w", ocfg.ErrInvalid, err)
+	}
+
+	changes := migrateRawConfig(raw)
+	if len(changes) == 0 {
+		fmt.Println("Config is already up to date")
+		return nil
+	}
+
+	migrated, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+
+	config, err := loadConfigData(migrated)
+	if err != nil {
+		return fmt.Errorf("migrated config is still invalid: %w", err)
+	}
+
+	if err := saveConfig(config, configPath); err != nil {
+		return err
+	}
+	if lastBackupPath != "" {
+		fmt.Printf("Backed up original config to: %s\n", lastBackupPath)
+	}
+
+	fmt.Println("\n=== Migrated Config ===")
+	for _, change := range changes {
+		fmt.Printf("  - %s\n", change)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrateConfigBacksUpOnce(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Chdir(t.TempDir())
+	withInput(t, "")
+	t.Cleanup(func() { configBackedUp, lastBackupPath = false, "" })
+
+	path := filepath.Join(home, ".config", "opencode", "opencode.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	data := "\xEF\xBB\xBF" + `{"provider": {"ollama": {"name": "Ollama", "models": ["qwen3-coder", "llama3.1",],},},}`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := migrateConfig(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	backups, err := listBackups(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 1 {
+		t.Errorf("%d backups, want 1: %v", len(backups), backups)
+	}
+
+	config, err := loadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(config.Provider["ollama"].Models); n != 2 {
+		t.Errorf("%d models after migrating, want 2", n)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import "strings"
+
+// knownModelLimits holds context and output limits for well-known models so
+// they can be offered as defaults when a model is added. Keys are matched
+// exactly first and then as a prefix of the model ID, longest first.
+var knownModelLimits = map[string]ModelLimit{
+	"gpt-4o":            {Context: 128000, Output: 16384},
+	"gpt-4o-mini":       {Context: 128000, Output: 16384},
+	"gpt-4.1":           {Context: 1047576, Output: 32768},
+	"gpt-4-turbo":       {Context: 128000, Output: 4096},
+	"gpt-3.5-turbo":     {Context: 16385, Output: 4096},
+	"o1":                {Context: 200000, Output: 100000},
+	"o3":                {Context: 200000, Output: 100000},
+	"o4-mini":           {Context: 200000, Output: 100000},
+	"claude-3-5-sonnet": {Context: 200000, Output: 8192},
+	"claude-3-5-haiku":  {Context: 200000, Output: 8192},
+	"claude-3-7-sonnet": {Context: 200000, Output: 64000},
+	"claude-sonnet-4":   {Context: 200000, Output: 64000},
+	"claude-opus-4":     {Context: 200000, Output: 32000},
+	"gemini-1.5-pro":    {Context: 2097152, Output: 8192},
+	"gemini-1.5-flash":  {Context: 1048576, Output: 8192},
+	"gemini-2.0-flash":  {Context: 1048576, Output: 8192},
+	"gemini-2.5-pro":    {Context: 1048576, Output: 65536},
+	"gemini-2.5-flash":  {Context: 1048576, Output: 65536},
+	"deepseek-chat":     {Context: 64000, Output: 8192},
+	"deepseek-reasoner": {Context: 64000, Output: 8192},
+	"deepseek-coder":    {Context: 128000, Output: 8192},
+	"qwen3-coder":       {Context: 262144, Output: 65536},
+	"qwen2.5-coder":     {Context: 32768, Output: 8192},
+	"llama3.1":          {Context: 131072, Output: 8192},
+	"llama3.2":          {Context: 131072, Output: 8192},
+	"llama3.3":          {Context: 131072, Output: 8192},
+	"llama3":            {Context: 8192, Output: 4096},
+	"mistral-large":     {Context: 131072, Output: 8192},
+	"codestral":         {Context: 256000, Output: 8192},
+	"devstral":          {Context: 131072, Output: 8192},
+}
+
+type tokenPreset struct {
+	label  string
+	tokens int
+}
+
+// contextPresets and outputPresets are offered when configuring limits by
+// hand.
+var contextPresets = []tokenPreset{
+	{"8k", 8192},
+	{"16k", 16384},
+	{"32k", 32768},
+	{"128k", 128000},
+	{"200k", 200000},
+}
+
+var outputPresets = []tokenPreset{
+	{"4k", 4096},
+	{"8k", 8192},
+	{"16k", 16384},
+	{"32k", 32768},
+	{"64k", 65536},
+}
+
+// lookupModelLimit finds the known limits for modelID, matching exactly or by
+// the longest known prefix, so tagged IDs such as "llama3.1:70b" still match.
+// Any vendor prefix (e.g. "openai/gpt-4o") is ignored.
+func lookupModelLimit(modelID string) (ModelLimit, string, bool) {
+	id := strings.ToLower(modelID)
+	if i := strings.LastIndex(id, "/"); i >= 0 {
+		id = id[i+1:]
+	}
+
+	if limit, ok := knownModelLimits[id]; ok {
+		return limit, id, true
+	}
+
+	match := ""
+	for known := range knownModelLimits {
+		if strings.HasPrefix(id, known) && len(known) > len(match) {
+			match = known
+		}
+	}
+	if match == "" {
+		return ModelLimit{}, "", false
+	}
+	return knownModelLimits[match], match, true
+}
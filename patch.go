@@ -0,0 +1,456 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	ocfg "github.com/liamwilliams93/opencode-config-wizard/pkg/config"
+)
+
+// mergePatch applies an RFC 7386 JSON merge patch to target and returns the
+// result: objects are merged recursively, null removes a key, and any other
+// value replaces the target outright.
+func mergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = make(map[string]interface{})
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = mergePatch(targetObject[key], value)
+	}
+	return targetObject
+}
+
+// patchOperation is a single RFC 6902 JSON patch operation.
+type patchOperation struct {
+	Op    string
+	Path  string
+	From  string
+	Value interface{}
+}
+
+// parsePatchOperations converts a decoded RFC 6902 document into operations,
+// checking each has the members its op requires.
+func parsePatchOperations(patch interface{}) ([]patchOperation, error) {
+	list, ok := patch.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("an RFC 6902 patch must be a JSON array of operations")
+	}
+
+	ops := make([]patchOperation, 0, len(list))
+	for i, item := range list {
+		fields, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("operation %d is not an object", i+1)
+		}
+
+		var op patchOperation
+		op.Op, _ = fields["op"].(string)
+		path, ok := fields["path"].(string)
+		if !ok {
+			return nil, fmt.Errorf("operation %d has no path", i+1)
+		}
+		op.Path = path
+
+		switch op.Op {
+		case "add", "replace", "test":
+			value, ok := fields["value"]
+			if !ok {
+				return nil, fmt.Errorf("operation %d (%s) has no value", i+1, op.Op)
+			}
+			op.Value = value
+		case "move", "copy":
+			from, ok := fields["from"].(string)
+			if !ok {
+				return nil, fmt.Errorf("operation %d (%s) has no from", i+1, op.Op)
+			}
+			op.From = from
+		case "remove":
+		default:
+			return nil, fmt.Errorf("operation %d has unknown op '%s'", i+1, op.Op)
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+// parsePointer splits an RFC 6901 JSON pointer into its unescaped tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%w JSON pointer '%s'", ocfg.ErrInvalid, pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// arrayIndex parses token as an index into an array of length n. allowEnd
+// permits n itself (and "-") for insertion.
+func arrayIndex(token string, n int, allowEnd bool) (int, error) {
+	if token == "-" && allowEnd {
+		return n, nil
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || index > n || (index == n && !allowEnd) {
+		return 0, fmt.Errorf("%w array index '%s'", ocfg.ErrInvalid, token)
+	}
+	return index, nil
+}
+
+func pointerGet(node interface{}, tokens []string) (interface{}, error) {
+	for _, token := range tokens {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			child, ok := n[token]
+			if !ok {
+				return nil, fmt.Errorf("path %w: '%s'", ocfg.ErrNotFound, token)
+			}
+			node = child
+		case []interface{}:
+			index, err := arrayIndex(token, len(n), false)
+			if err != nil {
+				return nil, err
+			}
+			node = n[index]
+		default:
+			return nil, fmt.Errorf("path %w: '%s'", ocfg.ErrNotFound, token)
+		}
+	}
+	return node, nil
+}
+
+// pointerAdd adds value at tokens and returns the updated node, inserting
+// into arrays and setting object members.
+func pointerAdd(node interface{}, tokens []string, value interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	token, rest := tokens[0], tokens[1:]
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if len(rest) == 0 {
+			n[token] = value
+			return n, nil
+		}
+		child, ok := n[token]
+		if !ok {
+			return nil, fmt.Errorf("path %w: '%s'", ocfg.ErrNotFound, token)
+		}
+		updated, err := pointerAdd(child, rest, value)
+		if err != nil {
+			return nil, err
+		}
+		n[token] = updated
+		return n, nil
+	case []interface{}:
+		index, err := arrayIndex(token, len(n), len(rest) == 0)
+		if err != nil {
+			return nil, err
+		}
+		if len(rest) == 0 {
+			n = append(n, nil)
+			copy(n[index+1:], n[index:])
+			n[index] = value
+			return n, nil
+		}
+		updated, err := pointerAdd(n[index], rest, value)
+		if err != nil {
+			return nil, err
+		}
+		n[index] = updated
+		return n, nil
+	}
+	return nil, fmt.Errorf("path %w: '%s'", ocfg.ErrNotFound, token)
+}
+
+// pointerRemove removes the value at tokens and returns the updated node.
+func pointerRemove(node interface{}, tokens []string) (interface{}, error) {
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("can't remove the whole config")
+	}
+	token, rest := tokens[0], tokens[1:]
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		child, ok := n[token]
+		if !ok {
+			return nil, fmt.Errorf("path %w: '%s'", ocfg.ErrNotFound, token)
+		}
+		if len(rest) == 0 {
+			delete(n, token)
+			return n, nil
+		}
+		updated, err := pointerRemove(child, rest)
+		if err != nil {
+			return nil, err
+		}
+		n[token] = updated
+		return n, nil
+	case []interface{}:
+		index, err := arrayIndex(token, len(n), false)
+		if err != nil {
+			return nil, err
+		}
+		if len(rest) == 0 {
+			return append(n[:index], n[index+1:]...), nil
+		}
+		updated, err := pointerRemove(n[index], rest)
+		if err != nil {
+			return nil, err
+		}
+		n[index] = updated
+		return n, nil
+	}
+	return nil, fmt.Errorf("path %w: '%s'", ocfg.ErrNotFound, token)
+}
+
+// deepCopyJSON copies a decoded JSON value so later edits don't alias it.
+func deepCopyJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			copied[key] = deepCopyJSON(item)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = deepCopyJSON(item)
+		}
+		return copied
+	}
+	return value
+}
+
+// applyPatchOperation applies a single RFC 6902 operation to doc and returns
+// the updated document.
+func applyPatchOperation(doc interface{}, op patchOperation) (interface{}, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add":
+		return pointerAdd(doc, path, op.Value)
+	case "remove":
+		return pointerRemove(doc, path)
+	case "replace":
+		if _, err := pointerGet(doc, path); err != nil {
+			return nil, err
+		}
+		if len(path) == 0 {
+			return op.Value, nil
+		}
+		if doc, err = pointerRemove(doc, path); err != nil {
+			return nil, err
+		}
+		return pointerAdd(doc, path, op.Value)
+	case "test":
+		actual, err := pointerGet(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !sameJSON(actual, op.Value) {
+			return nil, fmt.Errorf("test failed: value does not match")
+		}
+		return doc, nil
+	}
+
+	from, err := parsePointer(op.From)
+	if err != nil {
+		return nil, err
+	}
+	value, err := pointerGet(doc, from)
+	if err != nil {
+		return nil, err
+	}
+
+	if op.Op == "move" {
+		if op.Path == op.From {
+			return doc, nil
+		}
+		if strings.HasPrefix(op.Path, op.From+"/") {
+			return nil, fmt.Errorf("can't move a value into one of its own children")
+		}
+		if doc, err = pointerRemove(doc, from); err != nil {
+			return nil, err
+		}
+		return pointerAdd(doc, path, value)
+	}
+	return pointerAdd(doc, path, deepCopyJSON(value))
+}
+
+// readPatchFile reads a patch document from file, or from stdin if file is
+// "-".
+func readPatchFile(file string) (interface{}, error) {
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var patch interface{}
+	if err := json.Unmarshal(data, &patch); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return patch, nil
+}
+
+// checkConfigKeys rejects a patched config with top-level keys the wizard
+// doesn't know, since they would be dropped silently when it is saved.
+func checkConfigKeys(doc interface{}) error {
+	object, ok := doc.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%w patched config: it is not a JSON object", ocfg.ErrInvalid)
+	}
+
+	known := make(map[string]bool)
+	configType := reflect.TypeOf(ocfg.Config{})
+	for i := 0; i < configType.NumField(); i++ {
+		name, _, _ := strings.Cut(configType.Field(i).Tag.Get("json"), ",")
+		known[name] = true
+	}
+
+	var unknown []string
+	for key := range object {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("%w top-level key(s) %s: the config has no such field", ocfg.ErrInvalid, strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// loadRawConfig returns the config at path as generic JSON, so patches can
+// address it exactly as it is written.
+func loadRawConfig(path string) (interface{}, error) {
+	config, err := loadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+func patchConfig(args []string) error {
+	fs := flag.NewFlagSet("patch", flag.ContinueOnError)
+	rfc6902 := fs.Bool("rfc6902", false, "treat the file as an RFC 6902 list of operations")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("usage: patch [--rfc6902] <file|->")
+	}
+
+	patch, err := readPatchFile(positional[0])
+	if err != nil {
+		return err
+	}
+
+	configPath, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	raw, err := loadRawConfig(configPath)
+	if err != nil {
+		return err
+	}
+	before := readConfigQuietly(configPath)
+
+	var result interface{}
+	if *rfc6902 {
+		ops, err := parsePatchOperations(patch)
+		if err != nil {
+			return err
+		}
+		// Operations are applied to the in-memory copy only, so a failing
+		// operation leaves the file untouched.
+		for i, op := range ops {
+			if raw, err = applyPatchOperation(raw, op); err != nil {
+				return fmt.Errorf("operation %d (%s %s): %w", i+1, op.Op, op.Path, err)
+			}
+		}
+		result = raw
+	} else {
+		result = mergePatch(raw, patch)
+	}
+
+	if err := checkConfigKeys(result); err != nil {
+		return err
+	}
+
+	patched, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	config, err := loadConfigData(patched)
+	if err != nil {
+		return fmt.Errorf("patched config is invalid: %w", err)
+	}
+
+	changes := describeChanges(before, config)
+	if len(changes) == 0 {
+		fmt.Println("Patch made no changes")
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(configPath), 0700); err != nil {
+		return err
+	}
+
+	if err := saveConfig(config, configPath); err != nil {
+		return err
+	}
+
+	fmt.Println("\n=== Patched Config ===")
+	for _, change := range changes {
+		fmt.Printf("  - %s\n", change)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	ocfg "github.com/liamwilliams93/opencode-config-wizard/pkg/config"
+)
+
+func TestCheckConfigKeys(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		doc     interface{}
+		wantErr bool
+	}{
+		{"known keys", map[string]interface{}{"$schema": ocfg.SchemaURL, "provider": map[string]interface{}{}, "small_model": "a/b", "disabled_providers": []interface{}{}}, false},
+		{"unknown key", map[string]interface{}{"provider": map[string]interface{}{}, "theme": "dark"}, true},
+		{"not an object", []interface{}{}, true},
+	} {
+		err := checkConfigKeys(tc.doc)
+		if tc.wantErr && !errors.Is(err, ocfg.ErrInvalid) {
+			t.Errorf("%s: error %v, want ErrInvalid", tc.name, err)
+		}
+		if !tc.wantErr && err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+		}
+	}
+}
+
+func TestDescribeChangesSchema(t *testing.T) {
+	before, after := ocfg.New(), ocfg.New()
+	after.Schema = "https://example.com/config.json"
+
+	changes := describeChanges(before, after)
+	if len(changes) != 1 {
+		t.Fatalf("changes = %q, want one $schema change", changes)
+	}
+}
@@ -0,0 +1,309 @@
+// Package config reads, edits and writes opencode configuration files
+// (opencode.json). It has no interactive behaviour; the
+// opencode-config-wizard CLI is built on top of it.
+package config
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SchemaURL is where opencode publishes its config schema. New configs
+// reference it in $schema.
+const SchemaURL = "https://opencode.ai/config.json"
+
+// New returns an empty config with its sections initialized.
+func New() *Config {
+	return &Config{
+		Schema:   SchemaURL,
+		Provider: make(map[string]Provider),
+		MCP:      make(map[string]MCPServer),
+	}
+}
+
+// Load reads the config at path. A missing file is not an error; it yields an
+// empty config. Problems that were tolerated while parsing are returned as
+// warnings.
+func Load(path string) (*Config, []string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return New(), nil, nil
+		}
+		return nil, nil, err
+	}
+
+	config, warnings, err := Parse(data)
+	if err != nil {
+		var parseErr *ParseError
+		if errors.As(err, &parseErr) {
+			parseErr.Path = path
+		}
+		return nil, nil, err
+	}
+	return config, warnings, nil
+}
+
+// gzipMagic starts every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// Decompress returns data decompressed if it is gzip-compressed, and
+// unchanged otherwise.
+func Decompress(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, gzipMagic) {
+		return data, nil
+	}
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("reading gzip data: %w", err)
+	}
+	defer r.Close()
+	data, err = io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading gzip data: %w", err)
+	}
+	return data, nil
+}
+
+// Normalize turns config file contents into plain JSON that encoding/json
+// accepts: gzip-compressed data is decompressed, and a UTF-8 byte order mark
+// and trailing commas are removed. Removing trailing commas is reported as a
+// warning.
+func Normalize(data []byte) ([]byte, []string, error) {
+	var warnings []string
+
+	data, err := Decompress(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Editors on Windows sometimes save a UTF-8 byte order mark, which
+	// encoding/json rejects.
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	if fixed, ok := StripTrailingCommas(data); ok {
+		warnings = append(warnings, "config contains trailing commas; they were ignored and will be removed on the next save")
+		data = fixed
+	}
+	return data, warnings, nil
+}
+
+// Parse parses config JSON, filling in any missing sections. Gzip-compressed
+// input is decompressed first. A UTF-8 byte order mark and trailing commas
+// are tolerated, and known MCP type aliases are rewritten to their canonical
+// value; both are reported as warnings.
+func Parse(data []byte) (*Config, []string, error) {
+	config := New()
+
+	data, warnings, err := Normalize(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := json.Unmarshal(data, config); err != nil {
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &syntaxErr):
+			return nil, nil, NewParseError(data, syntaxErr.Offset, err)
+		case errors.As(err, &typeErr):
+			return nil, nil, NewParseError(data, typeErr.Offset, err)
+		}
+		return nil, nil, err
+	}
+
+	if config.Provider == nil {
+		config.Provider = make(map[string]Provider)
+	}
+
+	if config.MCP == nil {
+		config.MCP = make(map[string]MCPServer)
+	}
+
+	warnings = append(warnings, NormalizeMCPTypes(config)...)
+
+	return config, warnings, nil
+}
+
+// ParseError describes where in the config file parsing failed.
+type ParseError struct {
+	Path    string
+	Line    int
+	Column  int
+	Snippet string
+	Err     error
+}
+
+func (e *ParseError) Error() string {
+	location := fmt.Sprintf("line %d, column %d", e.Line, e.Column)
+	if e.Path != "" {
+		location = fmt.Sprintf("%s:%d:%d", e.Path, e.Line, e.Column)
+	}
+	msg := fmt.Sprintf("invalid config at %s: %v", location, e.Err)
+	if e.Snippet != "" {
+		msg += fmt.Sprintf("\n  %s\n  %s^", e.Snippet, strings.Repeat(" ", e.Column-1))
+	}
+	return msg
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// NewParseError converts the byte offset reported by encoding/json into a
+// line and column with the offending line as a snippet.
+func NewParseError(data []byte, offset int64, err error) *ParseError {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	lineStart := bytes.LastIndexByte(before, '\n') + 1
+	lineEnd := bytes.IndexByte(data[lineStart:], '\n')
+	if lineEnd < 0 {
+		lineEnd = len(data) - lineStart
+	}
+
+	column := int(offset) - lineStart
+	if column < 1 {
+		column = 1
+	}
+
+	snippet := strings.TrimRight(string(data[lineStart:lineStart+lineEnd]), "\r")
+	// Keep very long (e.g. minified) lines readable by showing only the
+	// text around the error.
+	const window = 60
+	if len(snippet) > 2*window {
+		start := column - window
+		if start < 0 {
+			start = 0
+		}
+		end := start + 2*window
+		if end > len(snippet) {
+			end = len(snippet)
+		}
+		snippet = snippet[start:end]
+		column -= start
+	}
+
+	return &ParseError{Line: line, Column: column, Snippet: snippet, Err: err}
+}
+
+// StripTrailingCommas removes commas that directly precede a closing brace or
+// bracket outside of string literals, a common hand-editing mistake. It
+// reports whether anything was removed.
+func StripTrailingCommas(data []byte) ([]byte, bool) {
+	out := make([]byte, 0, len(data))
+	inString := false
+	escaped := false
+	changed := false
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			out = append(out, c)
+			continue
+		}
+
+		if c == '"' {
+			inString = true
+		} else if c == ',' {
+			j := i + 1
+			for j < len(data) && (data[j] == ' ' || data[j] == '\t' || data[j] == '\n' || data[j] == '\r') {
+				j++
+			}
+			if j < len(data) && (data[j] == '}' || data[j] == ']') {
+				changed = true
+				continue
+			}
+		}
+		out = append(out, c)
+	}
+
+	return out, changed
+}
+
+// MCPTypeAliases maps MCP server types found in older or hand-edited configs
+// to the values opencode expects.
+var MCPTypeAliases = map[string]string{
+	"http":            "remote",
+	"sse":             "remote",
+	"streamable-http": "remote",
+	"streamableHttp":  "remote",
+	"stdio":           "local",
+}
+
+// NormalizeMCPTypes rewrites known MCP type aliases to their canonical value
+// and returns warnings for types opencode won't recognize.
+func NormalizeMCPTypes(config *Config) []string {
+	var warnings []string
+	for name, server := range config.MCP {
+		if canonical, ok := MCPTypeAliases[server.Type]; ok {
+			server.Type = canonical
+			config.MCP[name] = server
+			continue
+		}
+		if server.Type != "local" && server.Type != "remote" {
+			warnings = append(warnings, fmt.Sprintf("MCP server '%s' has unknown type '%s' (expected 'local' or 'remote')", name, server.Type))
+		}
+	}
+	return warnings
+}
+
+// DefaultIndent is the indentation opencode's own files use.
+const DefaultIndent = "  "
+
+// Marshal encodes config as JSON indented with indent, or minified if indent
+// is empty, with a trailing newline. encoding/json writes map keys in sorted
+// order, so providers, their models and MCP servers always come out sorted by
+// key and saving an unchanged config produces no diff.
+func Marshal(config *Config, indent string) ([]byte, error) {
+	var data []byte
+	var err error
+	if indent == "" {
+		data, err = json.Marshal(config)
+	} else {
+		data, err = json.MarshalIndent(config, "", indent)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// Save writes config to path, formatted as by Marshal. An existing file keeps
+// its permissions; a new one is created private, since configs may contain
+// API keys, and so is its directory if that is missing too.
+func Save(config *Config, path string, indent string) error {
+	data, err := Marshal(config, indent)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	perm := os.FileMode(0600)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	return os.WriteFile(path, data, perm)
+}
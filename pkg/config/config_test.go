@@ -0,0 +1,120 @@
+package config
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveEndsWithOneNewline(t *testing.T) {
+	for _, indent := range []string{"", "  "} {
+		path := filepath.Join(t.TempDir(), "opencode.json")
+		if err := Save(New(), path, indent); err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.HasSuffix(data, []byte("\n")) || bytes.HasSuffix(data, []byte("\n\n")) {
+			t.Errorf("indent %q: file ends with %q, want exactly one newline", indent, data[max(0, len(data)-3):])
+		}
+	}
+}
+
+func TestSaveCreatesPrivateFileAndDirectory(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "opencode")
+	path := filepath.Join(dir, "opencode.json")
+	if err := Save(New(), path, "  "); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		path string
+		want os.FileMode
+	}{
+		{path, 0600},
+		{dir, 0700},
+	} {
+		info, err := os.Stat(tc.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := info.Mode().Perm(); got != tc.want {
+			t.Errorf("%s: permissions %o, want %o", tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestParseSkipsBOM(t *testing.T) {
+	data := []byte("\xEF\xBB\xBF" + `{"$schema": "` + SchemaURL + `", "provider": {"ollama": {"name": "Ollama", "models": {"qwen3-coder": {"name": "Qwen3 Coder"}}}}}`)
+	config, _, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse with a BOM: %v", err)
+	}
+	if got := config.Provider["ollama"].Models["qwen3-coder"].Name; got != "Qwen3 Coder" {
+		t.Errorf("model name = %q, want %q", got, "Qwen3 Coder")
+	}
+}
+
+func TestMarshalSortsModelIDs(t *testing.T) {
+	config := New()
+	provider := Provider{Name: "Ollama", Models: make(map[string]Model)}
+	ids := []string{"qwen3-coder", "gpt-oss", "llama3.1", "deepseek-r1"}
+	for _, id := range ids {
+		provider.Models[id] = Model{Name: id}
+	}
+	config.Provider["ollama"] = provider
+
+	data, err := Marshal(config, "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"deepseek-r1", "gpt-oss", "llama3.1", "qwen3-coder"}
+	last := -1
+	for _, id := range want {
+		i := bytes.Index(data, []byte(`"`+id+`": {`))
+		if i < 0 {
+			t.Fatalf("model %q missing from output:\n%s", id, data)
+		}
+		if i < last {
+			t.Fatalf("models not in sorted ID order, want %v:\n%s", want, data)
+		}
+		last = i
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	var gzipped bytes.Buffer
+	w := gzip.NewWriter(&gzipped)
+	w.Write([]byte(`{"provider": {}}`))
+	w.Close()
+
+	for _, tc := range []struct {
+		name        string
+		input       []byte
+		want        string
+		wantWarning bool
+	}{
+		{"plain", []byte(`{"provider": {}}`), `{"provider": {}}`, false},
+		{"BOM", []byte("\xEF\xBB\xBF" + `{"provider": {}}`), `{"provider": {}}`, false},
+		{"trailing commas", []byte(`{"provider": {"a": [1, 2,],},}`), `{"provider": {"a": [1, 2]}}`, true},
+		{"gzip", gzipped.Bytes(), `{"provider": {}}`, false},
+	} {
+		data, warnings, err := Normalize(tc.input)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if string(data) != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, data, tc.want)
+		}
+		if got := len(warnings) > 0; got != tc.wantWarning {
+			t.Errorf("%s: warnings %v, want a warning: %v", tc.name, warnings, tc.wantWarning)
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrNotFound is returned when a provider, model or MCP server doesn't
+	// exist.
+	ErrNotFound = errors.New("not found")
+
+	// ErrExists is returned when adding something that already exists.
+	ErrExists = errors.New("already exists")
+
+	// ErrInvalid is returned for malformed input such as a bad model
+	// reference.
+	ErrInvalid = errors.New("invalid")
+)
+
+// CaseInsensitiveMatch returns the key in m that equals key when case is
+// ignored but is not identical to it, or "" if there is none. opencode treats
+// such keys as distinct, which is rarely what the user intended.
+func CaseInsensitiveMatch[V any](m map[string]V, key string) string {
+	for existing := range m {
+		if existing != key && strings.EqualFold(existing, key) {
+			return existing
+		}
+	}
+	return ""
+}
+
+// ModelRef returns the provider/model reference opencode uses for a model.
+func ModelRef(providerKey, modelID string) string {
+	return providerKey + "/" + modelID
+}
+
+// ParseModelRef splits a provider/model reference. Model IDs may themselves
+// contain slashes; only the first one separates the provider.
+func ParseModelRef(ref string) (providerKey, modelID string, err error) {
+	providerKey, modelID, ok := strings.Cut(ref, "/")
+	if !ok || providerKey == "" || modelID == "" {
+		return "", "", fmt.Errorf("%w model reference '%s': expected provider/model", ErrInvalid, ref)
+	}
+	return providerKey, modelID, nil
+}
+
+// ProviderKeys returns the keys of all configured providers, sorted.
+func (c *Config) ProviderKeys() []string {
+	keys := make([]string, 0, len(c.Provider))
+	for key := range c.Provider {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ModelRefs returns a provider/model reference for every configured model,
+// sorted.
+func (c *Config) ModelRefs() []string {
+	refs := []string{}
+	for providerKey, provider := range c.Provider {
+		for modelID := range provider.Models {
+			refs = append(refs, ModelRef(providerKey, modelID))
+		}
+	}
+	sort.Strings(refs)
+	return refs
+}
+
+// EmptyProviders returns the sorted keys of providers that have no models,
+// which opencode can't use.
+func (c *Config) EmptyProviders() []string {
+	var keys []string
+	for key, provider := range c.Provider {
+		if len(provider.Models) == 0 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ValidateModelRef checks that ref names a configured model, suggesting a
+// model whose ID differs only in case.
+func (c *Config) ValidateModelRef(ref string) error {
+	providerKey, modelID, err := ParseModelRef(ref)
+	if err != nil {
+		return err
+	}
+	provider, exists := c.Provider[providerKey]
+	if !exists {
+		return fmt.Errorf("provider '%s' %w", providerKey, ErrNotFound)
+	}
+	if _, exists := provider.Models[modelID]; !exists {
+		if match := CaseInsensitiveMatch(provider.Models, modelID); match != "" {
+			return fmt.Errorf("model '%s' %w in provider '%s' (did you mean '%s'?)", modelID, ErrNotFound, providerKey, match)
+		}
+		return fmt.Errorf("model '%s' %w in provider '%s'", modelID, ErrNotFound, providerKey)
+	}
+	return nil
+}
+
+// SetDefaultModel sets the model opencode starts with after checking that ref
+// names a configured model.
+func (c *Config) SetDefaultModel(ref string) error {
+	if err := c.ValidateModelRef(ref); err != nil {
+		return err
+	}
+	c.Model = ref
+	return nil
+}
+
+// SetSmallModel sets the model opencode uses for lightweight tasks after
+// checking that ref names a configured model.
+func (c *Config) SetSmallModel(ref string) error {
+	if err := c.ValidateModelRef(ref); err != nil {
+		return err
+	}
+	c.SmallModel = ref
+	return nil
+}
+
+// AddProvider adds provider under key. An existing provider with that key is
+// only replaced, models and all, if overwrite is set.
+func (c *Config) AddProvider(key string, provider Provider, overwrite bool) error {
+	if key == "" {
+		return fmt.Errorf("provider key is required")
+	}
+	if _, exists := c.Provider[key]; exists && !overwrite {
+		return fmt.Errorf("provider '%s' %w", key, ErrExists)
+	}
+	if provider.Models == nil {
+		provider.Models = make(map[string]Model)
+	}
+	c.Provider[key] = provider
+	return nil
+}
+
+// DeleteProvider removes the provider with the given key.
+func (c *Config) DeleteProvider(key string) error {
+	if _, exists := c.Provider[key]; !exists {
+		return fmt.Errorf("provider '%s' %w", key, ErrNotFound)
+	}
+	delete(c.Provider, key)
+	return nil
+}
+
+// AddModel adds model to an existing provider. An existing model with that
+// ID is only replaced if overwrite is set.
+func (c *Config) AddModel(providerKey, modelID string, model Model, overwrite bool) error {
+	if modelID == "" {
+		return fmt.Errorf("model ID is required")
+	}
+	provider, exists := c.Provider[providerKey]
+	if !exists {
+		return fmt.Errorf("provider '%s' %w", providerKey, ErrNotFound)
+	}
+	if _, exists := provider.Models[modelID]; exists && !overwrite {
+		return fmt.Errorf("model '%s' %w in provider '%s'", modelID, ErrExists, providerKey)
+	}
+
+	if provider.Models == nil {
+		provider.Models = make(map[string]Model)
+	}
+	provider.Models[modelID] = model
+	c.Provider[providerKey] = provider
+	return nil
+}
+
+// DeleteModel removes a model from a provider. If it was the default or small
+// model, that setting is cleared too; the cleared field names ("model",
+// "small_model") are returned.
+func (c *Config) DeleteModel(providerKey, modelID string) ([]string, error) {
+	provider, exists := c.Provider[providerKey]
+	if !exists {
+		return nil, fmt.Errorf("provider '%s' %w", providerKey, ErrNotFound)
+	}
+	if _, exists := provider.Models[modelID]; !exists {
+		return nil, fmt.Errorf("model '%s' %w in provider '%s'", modelID, ErrNotFound, providerKey)
+	}
+	delete(provider.Models, modelID)
+
+	var cleared []string
+	ref := ModelRef(providerKey, modelID)
+	if c.Model == ref {
+		c.Model = ""
+		cleared = append(cleared, "model")
+	}
+	if c.SmallModel == ref {
+		c.SmallModel = ""
+		cleared = append(cleared, "small_model")
+	}
+	return cleared, nil
+}
+
+// RenameModel changes a model's key within its provider, keeping its
+// definition, and points the default and small model at the new key if they
+// referenced the old one. An existing model with the new key is only replaced
+// if overwrite is set. The updated field names ("model", "small_model") are
+// returned.
+func (c *Config) RenameModel(providerKey, oldID, newID string, overwrite bool) ([]string, error) {
+	if newID == "" {
+		return nil, fmt.Errorf("model ID is required")
+	}
+	provider, exists := c.Provider[providerKey]
+	if !exists {
+		return nil, fmt.Errorf("provider '%s' %w", providerKey, ErrNotFound)
+	}
+	model, exists := provider.Models[oldID]
+	if !exists {
+		return nil, fmt.Errorf("model '%s' %w in provider '%s'", oldID, ErrNotFound, providerKey)
+	}
+	if oldID == newID {
+		return nil, nil
+	}
+	if _, exists := provider.Models[newID]; exists && !overwrite {
+		return nil, fmt.Errorf("model '%s' %w in provider '%s'", newID, ErrExists, providerKey)
+	}
+
+	provider.Models[newID] = model
+	delete(provider.Models, oldID)
+
+	var updated []string
+	oldRef, newRef := ModelRef(providerKey, oldID), ModelRef(providerKey, newID)
+	if c.Model == oldRef {
+		c.Model = newRef
+		updated = append(updated, "model")
+	}
+	if c.SmallModel == oldRef {
+		c.SmallModel = newRef
+		updated = append(updated, "small_model")
+	}
+	return updated, nil
+}
+
+// AddMCPServer adds server under name. An existing server with that name is
+// only replaced if overwrite is set.
+func (c *Config) AddMCPServer(name string, server MCPServer, overwrite bool) error {
+	if name == "" {
+		return fmt.Errorf("MCP server name is required")
+	}
+	if server.Type != "local" && server.Type != "remote" {
+		return fmt.Errorf("MCP server '%s' has unknown type '%s' (expected 'local' or 'remote')", name, server.Type)
+	}
+	if _, exists := c.MCP[name]; exists && !overwrite {
+		return fmt.Errorf("MCP server '%s' %w", name, ErrExists)
+	}
+	c.MCP[name] = server
+	return nil
+}
+
+// DeleteMCPServer removes the MCP server with the given name.
+func (c *Config) DeleteMCPServer(name string) error {
+	if _, exists := c.MCP[name]; !exists {
+		return fmt.Errorf("MCP server '%s' %w", name, ErrNotFound)
+	}
+	delete(c.MCP, name)
+	return nil
+}
+
+// Merge returns the config opencode ends up using when override, such as a
+// project config, is layered over base, such as the global config. Providers,
+// their options and models, and MCP servers are merged key by key with
+// override winning; the other settings come from override when it sets them.
+// Neither config is modified.
+func Merge(base, override *Config) *Config {
+	merged := New()
+	merged.Schema = base.Schema
+	merged.Model = base.Model
+	merged.SmallModel = base.SmallModel
+	merged.EnabledProviders = append([]string(nil), base.EnabledProviders...)
+	merged.DisabledProviders = append([]string(nil), base.DisabledProviders...)
+
+	for _, config := range []*Config{base, override} {
+		for key, provider := range config.Provider {
+			existing, exists := merged.Provider[key]
+			if !exists {
+				existing = Provider{
+					Options: make(map[string]interface{}),
+					Models:  make(map[string]Model),
+				}
+			}
+			if provider.NPM != "" {
+				existing.NPM = provider.NPM
+			}
+			if provider.Name != "" {
+				existing.Name = provider.Name
+			}
+			for name, value := range provider.Options {
+				existing.Options[name] = value
+			}
+			for modelID, model := range provider.Models {
+				existing.Models[modelID] = model
+			}
+			merged.Provider[key] = existing
+		}
+		for name, server := range config.MCP {
+			merged.MCP[name] = server
+		}
+	}
+
+	if override.Schema != "" {
+		merged.Schema = override.Schema
+	}
+	if override.Model != "" {
+		merged.Model = override.Model
+	}
+	if override.SmallModel != "" {
+		merged.SmallModel = override.SmallModel
+	}
+	if len(override.EnabledProviders) > 0 {
+		merged.EnabledProviders = append([]string(nil), override.EnabledProviders...)
+	}
+	if len(override.DisabledProviders) > 0 {
+		merged.DisabledProviders = append([]string(nil), override.DisabledProviders...)
+	}
+	return merged
+}
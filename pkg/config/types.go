@@ -0,0 +1,61 @@
+package config
+
+// Config is an opencode.json configuration.
+type Config struct {
+	Schema            string               `json:"$schema"`
+	Provider          map[string]Provider  `json:"provider"`
+	Model             string               `json:"model,omitempty"`
+	SmallModel        string               `json:"small_model,omitempty"`
+	EnabledProviders  []string             `json:"enabled_providers,omitempty"`
+	DisabledProviders []string             `json:"disabled_providers,omitempty"`
+	MCP               map[string]MCPServer `json:"mcp,omitempty"`
+}
+
+// Provider is a model provider, keyed by its ID in Config.Provider.
+type Provider struct {
+	NPM     string                 `json:"npm"`
+	Name    string                 `json:"name"`
+	Options map[string]interface{} `json:"options"`
+	Models  map[string]Model       `json:"models"`
+}
+
+// Model is a model offered by a provider, keyed by its ID in Provider.Models.
+type Model struct {
+	Name  string      `json:"name"`
+	ID    string      `json:"id,omitempty"`
+	Limit *ModelLimit `json:"limit,omitempty"`
+	Cost  *ModelCost  `json:"cost,omitempty"`
+	ModelCapabilities
+}
+
+// ModelCapabilities is embedded in Model so its fields sit alongside the other
+// model properties in the JSON, as opencode expects. Unset flags are omitted.
+type ModelCapabilities struct {
+	ToolCall   *bool `json:"tool_call,omitempty"`
+	Reasoning  *bool `json:"reasoning,omitempty"`
+	Attachment *bool `json:"attachment,omitempty"`
+}
+
+// ModelLimit is a model's context window and maximum output in tokens.
+type ModelLimit struct {
+	Context int `json:"context,omitempty"`
+	Output  int `json:"output,omitempty"`
+}
+
+// ModelCost is a model's price in USD per million tokens.
+type ModelCost struct {
+	Input  float64 `json:"input,omitempty"`
+	Output float64 `json:"output,omitempty"`
+}
+
+// MCPServer is a local (command) or remote (URL) MCP server.
+type MCPServer struct {
+	Type        string                 `json:"type"`
+	Command     []string               `json:"command,omitempty"`
+	Environment map[string]string      `json:"environment,omitempty"`
+	URL         string                 `json:"url,omitempty"`
+	Headers     map[string]string      `json:"headers,omitempty"`
+	OAuth       map[string]interface{} `json:"oauth,omitempty"`
+	Enabled     *bool                  `json:"enabled,omitempty"`
+	Timeout     *int                   `json:"timeout,omitempty"`
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"compress/gzip"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	ocfg "github.com/liamwilliams93/opencode-config-wizard/pkg/config"
+)
+
+// isLocalURL reports whether rawURL points at this machine.
+func isLocalURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := u.Hostname()
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// isSecretReference reports whether value refers to a secret rather than
+// containing one, either in opencode's {env:VAR} form or as ${VAR}.
+func isSecretReference(value string) bool {
+	return (strings.HasPrefix(value, "{env:") && strings.HasSuffix(value, "}")) ||
+		placeholderPattern.MatchString(value)
+}
+
+// envVarName turns a provider or server key and a suffix into an environment
+// variable name, e.g. "my-provider" and "API_KEY" become "MY_PROVIDER_API_KEY".
+func envVarName(key, suffix string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, strings.ToUpper(key+"_"+suffix))
+}
+
+// secretPlaceholder returns the ${VAR} reference a secret is replaced with.
+func secretPlaceholder(key, suffix string) string {
+	return "${" + envVarName(key, suffix) + "}"
+}
+
+// redactSecrets replaces every secret in config with a ${VAR} placeholder:
+// provider API keys and headers, and MCP server headers, environment values
+// and OAuth client secrets. Values that are already references are kept. It
+// returns a line describing each replacement, sorted.
+func redactSecrets(config *Config) []string {
+	var replaced []string
+
+	for key, provider := range config.Provider {
+		if apiKey, ok := provider.Options["apiKey"].(string); ok && apiKey != "" && !isSecretReference(apiKey) {
+			placeholder := secretPlaceholder(key, "API_KEY")
+			provider.Options["apiKey"] = placeholder
+			replaced = append(replaced, fmt.Sprintf("Replaced API key for %s with %s", key, placeholder))
+		}
+
+		var headers map[string]interface{}
+		switch raw := provider.Options["headers"].(type) {
+		case map[string]string:
+			headers = make(map[string]interface{}, len(raw))
+			for name, value := range raw {
+				headers[name] = value
+			}
+		case map[string]interface{}:
+			headers = raw
+		}
+		for name, value := range headers {
+			if s, ok := value.(string); ok && isSecretReference(s) {
+				continue
+			}
+			placeholder := secretPlaceholder(key, name)
+			headers[name] = placeholder
+			replaced = append(replaced, fmt.Sprintf("Replaced header %s for %s with %s", name, key, placeholder))
+		}
+		if headers != nil {
+			provider.Options["headers"] = headers
+		}
+	}
+
+	for name, server := range config.MCP {
+		for header, value := range server.Headers {
+			if !isSecretReference(value) {
+				placeholder := secretPlaceholder(name, header)
+				server.Headers[header] = placeholder
+				replaced = append(replaced, fmt.Sprintf("Replaced header %s for %s with %s", header, name, placeholder))
+			}
+		}
+		for variable, value := range server.Environment {
+			if !isSecretReference(value) {
+				placeholder := secretPlaceholder(name, variable)
+				server.Environment[variable] = placeholder
+				replaced = append(replaced, fmt.Sprintf("Replaced environment variable %s for %s with %s", variable, name, placeholder))
+			}
+		}
+		if secret, ok := server.OAuth["clientSecret"].(string); ok && secret != "" && !isSecretReference(secret) {
+			placeholder := secretPlaceholder(name, "CLIENT_SECRET")
+			server.OAuth["clientSecret"] = placeholder
+			replaced = append(replaced, fmt.Sprintf("Replaced OAuth client secret for %s with %s", name, placeholder))
+		}
+	}
+
+	sort.Strings(replaced)
+	return replaced
+}
+
+// samePath reports whether a and b name the same file, following symlinks.
+func samePath(a, b string) bool {
+	aInfo, aErr := os.Stat(a)
+	bInfo, bErr := os.Stat(b)
+	if aErr == nil && bErr == nil {
+		return os.SameFile(aInfo, bInfo)
+	}
+	aAbs, aErr := filepath.Abs(a)
+	bAbs, bErr := filepath.Abs(b)
+	return aErr == nil && bErr == nil && aAbs == bAbs
+}
+
+func cloneConfig(args []string) error {
+	fs := flag.NewFlagSet("clone-config", flag.ContinueOnError)
+	out := fs.String("out", "", "file to write the portable config to")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	configPath, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		*out = promptString("Output file", "opencode-portable.json")
+	}
+	if samePath(*out, configPath) {
+		return fmt.Errorf("%w output file %s: it is the active config", ocfg.ErrInvalid, *out)
+	}
+
+	fmt.Println("\n=== Clone Config ===")
+
+	for key, provider := range config.Provider {
+		baseURL, _ := provider.Options["baseURL"].(string)
+		if isLocalURL(baseURL) && !promptBool(fmt.Sprintf("Include local provider '%s' (%s)?", key, baseURL), false) {
+			delete(config.Provider, key)
+			fmt.Printf("Dropped provider: %s\n", key)
+		}
+	}
+
+	for _, line := range redactSecrets(config) {
+		fmt.Println(line)
+	}
+
+	if config.Model != "" {
+		if _, exists := config.Provider[modelRefProvider(config.Model)]; !exists {
+			config.Model = ""
+		}
+	}
+	if config.SmallModel != "" {
+		if _, exists := config.Provider[modelRefProvider(config.SmallModel)]; !exists {
+			config.SmallModel = ""
+		}
+	}
+
+	if err := saveConfig(config, *out); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nPortable config written to: %s\n", *out)
+	fmt.Println("Set the referenced environment variables on the new machine, then run 'import' with this file.")
+	return nil
+}
+
+func importConfig(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fromStdin := fs.Bool("stdin", false, "read the config from standard input and replace without asking")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 1 || (*fromStdin && len(positional) != 0) {
+		return fmt.Errorf("usage: import <file> | import --stdin")
+	}
+
+	var data []byte
+	file := "stdin"
+	if *fromStdin {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		if len(positional) == 1 {
+			file = positional[0]
+		} else {
+			file = promptString("Config file to import", "")
+			if file == "" {
+				return errCancelled
+			}
+		}
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return err
+	}
+
+	config, err := loadConfigData(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+
+	configPath, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(configPath); err == nil {
+		// Stdin holds the config, so there is no one to ask.
+		if !*fromStdin && !promptBool(fmt.Sprintf("Replace the existing config at %s?", configPath), false) {
+			return errCancelled
+		}
+		backupPath, err := backupConfig(configPath)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Backed up existing config to: %s\n", backupPath)
+	} else if err := os.MkdirAll(filepath.Dir(configPath), 0700); err != nil {
+		return err
+	}
+
+	if err := saveConfig(config, configPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("Imported %d provider(s) and %d MCP server(s) into: %s\n", len(config.Provider), len(config.MCP), configPath)
+	return nil
+}
+
+// exportConfig writes the config to stdout, for piping into import --stdin.
+func exportConfig(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	compress := fs.Bool("gzip", false, "gzip-compress the output")
+	merged := fs.Bool("merged", false, "export the project config layered over the global one")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return fmt.Errorf("usage: export [--gzip] [--merged]")
+	}
+
+	var config *Config
+	if *merged {
+		config, _, _, err = loadMergedConfig()
+	} else {
+		var configPath string
+		if configPath, err = getConfigPath(); err == nil {
+			config, err = loadConfig(configPath)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	indent, err := outputIndent()
+	if err != nil {
+		return err
+	}
+	data, err := ocfg.Marshal(config, indent)
+	if err != nil {
+		return err
+	}
+
+	if !*compress {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+
+	w := gzip.NewWriter(os.Stdout)
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	return w.Close()
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	ocfg "github.com/liamwilliams93/opencode-config-wizard/pkg/config"
+)
+
+func TestRedactSecrets(t *testing.T) {
+	config := ocfg.New()
+	config.Provider["my-provider"] = Provider{
+		Name: "Mine",
+		Options: map[string]interface{}{
+			"apiKey":  "sk-secret",
+			"baseURL": "https://api.example.com/v1",
+			"headers": map[string]interface{}{
+				"X-Api-Key": "secret",
+				"X-Org":     "${ORG_ID}",
+				"X-Retries": float64(3),
+			},
+		},
+	}
+	config.Provider["env"] = Provider{
+		Name:    "Env",
+		Options: map[string]interface{}{"apiKey": "{env:ENV_API_KEY}"},
+	}
+	config.MCP["github"] = MCPServer{
+		Type:        "local",
+		Command:     []string{"github-mcp"},
+		Environment: map[string]string{"GITHUB_TOKEN": "ghp_secret"},
+	}
+	config.MCP["remote"] = MCPServer{
+		Type:    "remote",
+		URL:     "https://mcp.example.com",
+		Headers: map[string]string{"Authorization": "Bearer secret"},
+		OAuth:   map[string]interface{}{"clientId": "id", "clientSecret": "secret"},
+	}
+
+	redactSecrets(config)
+
+	headers := config.Provider["my-provider"].Options["headers"].(map[string]interface{})
+	for _, tc := range []struct {
+		field string
+		got   interface{}
+		want  string
+	}{
+		{"provider apiKey", config.Provider["my-provider"].Options["apiKey"], "${MY_PROVIDER_API_KEY}"},
+		{"provider baseURL", config.Provider["my-provider"].Options["baseURL"], "https://api.example.com/v1"},
+		{"provider header", headers["X-Api-Key"], "${MY_PROVIDER_X_API_KEY}"},
+		{"provider header reference", headers["X-Org"], "${ORG_ID}"},
+		{"provider non-string header", headers["X-Retries"], "${MY_PROVIDER_X_RETRIES}"},
+		{"apiKey reference", config.Provider["env"].Options["apiKey"], "{env:ENV_API_KEY}"},
+		{"MCP environment", config.MCP["github"].Environment["GITHUB_TOKEN"], "${GITHUB_GITHUB_TOKEN}"},
+		{"MCP header", config.MCP["remote"].Headers["Authorization"], "${REMOTE_AUTHORIZATION}"},
+		{"MCP OAuth client secret", config.MCP["remote"].OAuth["clientSecret"], "${REMOTE_CLIENT_SECRET}"},
+		{"MCP OAuth client ID", config.MCP["remote"].OAuth["clientId"], "id"},
+	} {
+		if tc.got != tc.want {
+			t.Errorf("%s = %v, want %q", tc.field, tc.got, tc.want)
+		}
+	}
+}
+
+func TestSamePath(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "opencode.json")
+	for _, tc := range []struct {
+		other string
+		want  bool
+	}{
+		{path, true},
+		{filepath.Join(dir, ".", "opencode.json"), true},
+		{filepath.Join(dir, "portable.json"), false},
+	} {
+		if got := samePath(tc.other, path); got != tc.want {
+			t.Errorf("samePath(%q, %q) = %v, want %v", tc.other, path, got, tc.want)
+		}
+	}
+}
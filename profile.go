@@ -0,0 +1,326 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	ocfg "github.com/liamwilliams93/opencode-config-wizard/pkg/config"
+)
+
+// defaultProfile is the profile a config that isn't a profile yet is saved as
+// when switching away from it.
+const defaultProfile = "default"
+
+// getProfilesDir returns the directory named profiles are stored in, next to
+// the global config.
+func getProfilesDir() (string, error) {
+	configPath, err := getGlobalConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), "profiles"), nil
+}
+
+func getProfilePath(name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("%w profile name '%s'", ocfg.ErrInvalid, name)
+	}
+	dir, err := getProfilesDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
+
+// listProfileNames returns the names of all saved profiles, sorted.
+func listProfileNames() ([]string, error) {
+	dir, err := getProfilesDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		names = append(names, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// activeProfile returns the name of the profile the global config is
+// symlinked to, or "" if it is a regular file.
+func activeProfile() string {
+	configPath, err := getGlobalConfigPath()
+	if err != nil {
+		return ""
+	}
+	return profileOf(configPath)
+}
+
+// profileOf returns the name of the profile path is, or is a symlink to, or
+// "" if it isn't a profile.
+func profileOf(path string) string {
+	dir, err := getProfilesDir()
+	if err != nil {
+		return ""
+	}
+	if target, err := os.Readlink(path); err == nil {
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(path), target)
+		}
+		path = target
+	}
+	if filepath.Dir(path) != dir || filepath.Ext(path) != ".json" {
+		return ""
+	}
+	return strings.TrimSuffix(filepath.Base(path), ".json")
+}
+
+// matchingProfile returns the name of a saved profile holding the same config
+// as data, or "" if there is none. Formatting differences are ignored, since
+// 'profile new' rewrites the config as it saves it.
+func matchingProfile(data []byte) string {
+	names, err := listProfileNames()
+	if err != nil {
+		return ""
+	}
+	config, _, parseErr := ocfg.Parse(data)
+	for _, name := range names {
+		profilePath, err := getProfilePath(name)
+		if err != nil {
+			continue
+		}
+		saved, err := os.ReadFile(profilePath)
+		if err != nil {
+			continue
+		}
+		if bytes.Equal(saved, data) {
+			return name
+		}
+		if parseErr == nil {
+			if profile, _, err := ocfg.Parse(saved); err == nil && sameJSON(profile, config) {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// saveUnsavedConfig makes sure the regular file at configPath is kept as a
+// profile before useProfile replaces it. If no profile already holds the same
+// contents, it is saved as defaultProfile; if that name is taken, the switch
+// is refused rather than risk losing the config.
+func saveUnsavedConfig(configPath string) error {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return err
+	}
+	if matchingProfile(data) != "" {
+		return nil
+	}
+
+	profilePath, err := getProfilePath(defaultProfile)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(profilePath); err == nil {
+		return fmt.Errorf("the current config isn't saved as a profile and profile '%s' already exists; run 'profile new <name>' first", defaultProfile)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(profilePath), 0700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(profilePath, data, 0600); err != nil {
+		return err
+	}
+	recordSave(profilePath, nil, nil)
+	fmt.Printf("Saved the current config as profile '%s'\n", defaultProfile)
+	return nil
+}
+
+func profileCommand(args []string) error {
+	if useLocalConfig && !projectConfigDetected {
+		return fmt.Errorf("profiles apply to the global config and can't be used with --local")
+	}
+
+	if len(args) == 0 {
+		return fmt.Errorf("usage: profile <list|new|use|delete> [name]")
+	}
+
+	sub, rest := args[0], args[1:]
+	if sub == "list" {
+		if len(rest) != 0 {
+			return fmt.Errorf("usage: profile list")
+		}
+		return listProfiles()
+	}
+
+	actions := map[string]func(name string) error{
+		"new":    newProfile,
+		"use":    useProfile,
+		"delete": deleteProfile,
+	}
+	action, ok := actions[sub]
+	if !ok {
+		return fmt.Errorf("unknown profile command '%s' (expected list, new, use or delete)", sub)
+	}
+	if len(rest) != 1 {
+		return fmt.Errorf("usage: profile %s <name>", sub)
+	}
+	return action(rest[0])
+}
+
+func listProfiles() error {
+	names, err := listProfileNames()
+	if err != nil {
+		return err
+	}
+
+	if len(names) == 0 {
+		fmt.Println("No profiles saved")
+		return nil
+	}
+
+	active := activeProfile()
+
+	fmt.Println("\n=== Profiles ===")
+	for _, name := range names {
+		if name == active {
+			fmt.Printf("* %s %s\n", name, green("(active)"))
+		} else {
+			fmt.Printf("  %s\n", name)
+		}
+	}
+	return nil
+}
+
+// newProfile saves the current global config as a new profile so it can be
+// edited and switched to independently.
+func newProfile(name string) error {
+	profilePath, err := getProfilePath(name)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(profilePath); err == nil {
+		return fmt.Errorf("profile '%s' already exists", name)
+	}
+
+	configPath, err := getGlobalConfigPath()
+	if err != nil {
+		return err
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(profilePath), 0700); err != nil {
+		return err
+	}
+
+	if err := saveConfig(config, profilePath); err != nil {
+		return err
+	}
+
+	fmt.Printf("Profile '%s' created from the current config\n", name)
+	fmt.Printf("Run 'profile use %s' to switch to it\n", name)
+	return nil
+}
+
+// useProfile points the global config at a profile with a symlink so every
+// other command reads and writes the profile directly. Where symlinks aren't
+// available the profile is copied instead.
+func useProfile(name string) error {
+	profilePath, err := getProfilePath(name)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(profilePath); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("profile '%s' %w", name, ocfg.ErrNotFound)
+		}
+		return err
+	}
+
+	configPath, err := getGlobalConfigPath()
+	if err != nil {
+		return err
+	}
+
+	current := readConfigQuietly(configPath)
+	info, err := os.Lstat(configPath)
+	switch {
+	case err == nil && info.Mode()&os.ModeSymlink == 0:
+		// The config isn't a profile yet, so keep it as one before replacing
+		// it; a backup alone would eventually be pruned.
+		if err := saveUnsavedConfig(configPath); err != nil {
+			return err
+		}
+	case err != nil && !os.IsNotExist(err):
+		return err
+	}
+
+	if err := os.Remove(configPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := os.Symlink(profilePath, configPath); err != nil {
+		data, err := os.ReadFile(profilePath)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(configPath, data, 0600); err != nil {
+			return err
+		}
+		recordSave(configPath, current, readConfigQuietly(configPath))
+		fmt.Printf("Switched to profile '%s' (copied; changes won't be saved back to the profile)\n", name)
+		return nil
+	}
+
+	recordSave(configPath, current, readConfigQuietly(configPath))
+	fmt.Printf("Switched to profile '%s'\n", name)
+	return nil
+}
+
+func deleteProfile(name string) error {
+	profilePath, err := getProfilePath(name)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(profilePath); os.IsNotExist(err) {
+		return fmt.Errorf("profile '%s' %w", name, ocfg.ErrNotFound)
+	}
+
+	if activeProfile() == name {
+		return fmt.Errorf("profile '%s' is active; switch to another profile first", name)
+	}
+
+	if !promptBool(fmt.Sprintf("Delete profile '%s'?", name), false) {
+		return errCancelled
+	}
+
+	if err := os.Remove(profilePath); err != nil {
+		return err
+	}
+
+	fmt.Printf("Profile '%s' deleted\n", name)
+	return nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUseProfileKeepsUnsavedConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Chdir(t.TempDir())
+	withInput(t, "")
+	t.Cleanup(func() { savedConfigs = commandResult{} })
+
+	dir := filepath.Join(home, ".config", "opencode")
+	configPath := filepath.Join(dir, "opencode.json")
+	if err := os.MkdirAll(filepath.Join(dir, "profiles"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	current := []byte(`{"provider": {"ollama": {"name": "Ollama"}}}`)
+	if err := os.WriteFile(configPath, current, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "profiles", "work.json"), []byte(`{"provider": {}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := useProfile("work"); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := os.ReadFile(filepath.Join(dir, "profiles", defaultProfile+".json"))
+	if err != nil {
+		t.Fatalf("current config not saved as profile '%s': %v", defaultProfile, err)
+	}
+	if string(saved) != string(current) {
+		t.Errorf("profile '%s' = %s, want %s", defaultProfile, saved, current)
+	}
+	if got := activeProfile(); got != "work" {
+		t.Errorf("active profile %q, want %q", got, "work")
+	}
+	if got, want := getBackupDir(configPath), filepath.Join(dir, "profiles", "work", "backups"); got != want {
+		t.Errorf("backup dir %s, want %s", got, want)
+	}
+
+	// Switching back and forth again must not need another profile, since
+	// both configs are saved now.
+	if err := useProfile(defaultProfile); err != nil {
+		t.Fatal(err)
+	}
+	if err := useProfile("work"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -2,22 +2,131 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"os"
+	"regexp"
+	"strconv"
 	"strings"
+
+	"golang.org/x/term"
 )
 
+var stdinScanner = bufio.NewScanner(os.Stdin)
+
+// stdinClosed records that the last read found the end of stdin, so a loop
+// that asks again on blank input can stop.
+var stdinClosed bool
+
+// nonInteractive makes any prompt a fatal error instead of waiting for input,
+// so automation that forgot a flag fails instead of hanging; set by the
+// --non-interactive flag.
+var nonInteractive bool
+
+// assumeYes skips the confirmation before saving at the end of long flows;
+// set by the --yes flag.
+var assumeYes bool
+
+// confirmSave asks whether to save the changes summarized just before, unless
+// --yes was given.
+func confirmSave() bool {
+	return assumeYes || promptBool("\nSave these changes?", true)
+}
+
+// errInputRequired is reported when a prompt is reached with nonInteractive
+// set.
+var errInputRequired = errors.New("input required")
+
+// requireInteractive exits with an error naming the prompt if prompting is
+// disabled. Nothing has been saved at that point, since commands only save
+// once all their input has been read.
+func requireInteractive(prompt string) {
+	if !nonInteractive {
+		return
+	}
+	err := fmt.Errorf("%w for '%s' but --non-interactive is set; pass it with a flag or argument", errInputRequired, prompt)
+	if jsonErrors {
+		printJSONError(err)
+	} else {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	}
+	os.Exit(exitCode(err))
+}
+
+// lineEditor edits prompt input on a terminal. It is shared by all prompts so
+// the up arrow recalls earlier answers from the same session.
+var lineEditor *term.Terminal
+
+// terminalState is the terminal mode to restore while lineEditor has put the
+// terminal in raw mode.
+var terminalState *term.State
+
+// interruptReader reads the terminal for lineEditor. Raw mode stops Ctrl-C
+// from sending SIGINT, so it is handled here the way the signal would be:
+// nothing is saved and the wizard exits.
+type interruptReader struct{}
+
+func (interruptReader) Read(p []byte) (int, error) {
+	n, err := os.Stdin.Read(p)
+	if bytes.IndexByte(p[:n], 3) >= 0 {
+		term.Restore(int(os.Stdin.Fd()), terminalState)
+		fmt.Println("\nCancelled")
+		os.Exit(exitCancelled)
+	}
+	return n, err
+}
+
+// readLine shows prompt and reads a single line from stdin. On a terminal the
+// line can be edited with the arrow keys, Ctrl-W and the other usual keys,
+// and earlier answers recalled with the up arrow. Otherwise a shared scanner
+// is used so that buffered input is not lost between prompts when stdin is a
+// pipe.
+func readLine(prompt string) string {
+	// The editor redraws only the last line of the prompt.
+	if i := strings.LastIndex(prompt, "\n"); i >= 0 {
+		fmt.Print(prompt[:i+1])
+		prompt = prompt[i+1:]
+	}
+
+	stdin, stdout := int(os.Stdin.Fd()), int(os.Stdout.Fd())
+	if term.IsTerminal(stdin) && term.IsTerminal(stdout) {
+		if state, err := term.MakeRaw(stdin); err == nil {
+			terminalState = state
+			defer term.Restore(stdin, state)
+
+			if lineEditor == nil {
+				lineEditor = term.NewTerminal(struct {
+					io.Reader
+					io.Writer
+				}{interruptReader{}, os.Stdout}, "")
+			}
+			lineEditor.SetPrompt(prompt)
+			line, err := lineEditor.ReadLine()
+			if err != nil && !errors.Is(err, term.ErrPasteIndicator) {
+				stdinClosed = errors.Is(err, io.EOF)
+				return ""
+			}
+			return strings.TrimSpace(line)
+		}
+	}
+
+	fmt.Print(prompt)
+	stdinClosed = !stdinScanner.Scan()
+	return strings.TrimSpace(stdinScanner.Text())
+}
+
 func promptString(prompt string, defaultValue string) string {
+	requireInteractive(prompt)
+	var input string
 	if defaultValue != "" {
-		fmt.Printf("%s [%s]: ", prompt, defaultValue)
+		input = readLine(fmt.Sprintf("%s [%s]: ", prompt, defaultValue))
 	} else {
-		fmt.Printf("%s: ", prompt)
+		input = readLine(prompt + ": ")
 	}
 
-	scanner := bufio.NewScanner(os.Stdin)
-	scanner.Scan()
-	input := strings.TrimSpace(scanner.Text())
-
 	if input == "" {
 		return defaultValue
 	}
@@ -25,19 +134,153 @@ func promptString(prompt string, defaultValue string) string {
 }
 
 func promptBool(prompt string, defaultValue bool) bool {
+	requireInteractive(prompt)
 	defaultStr := "n"
 	if defaultValue {
 		defaultStr = "y"
 	}
 
-	fmt.Printf("%s [%s] (y/n): ", prompt, defaultStr)
-
-	scanner := bufio.NewScanner(os.Stdin)
-	scanner.Scan()
-	input := strings.TrimSpace(scanner.Text())
+	input := readLine(fmt.Sprintf("%s [%s] (y/n): ", prompt, defaultStr))
 
 	if input == "" {
 		return defaultValue
 	}
 	return input == "y" || input == "Y"
 }
+
+// promptInt reads a positive integer, re-prompting until the input is valid.
+// Blank input returns defaultValue, which may be 0 to mean "not set".
+func promptInt(prompt string, defaultValue int) int {
+	for {
+		defaultStr := ""
+		if defaultValue > 0 {
+			defaultStr = strconv.Itoa(defaultValue)
+		}
+
+		input := promptString(prompt, defaultStr)
+		if input == "" {
+			return defaultValue
+		}
+
+		value, err := strconv.Atoi(input)
+		if err != nil || value <= 0 {
+			fmt.Printf("Invalid value '%s': enter a positive whole number\n", input)
+			continue
+		}
+		return value
+	}
+}
+
+// promptPrice reads a price in USD, re-prompting until it is a non-negative
+// number. Blank input returns 0.
+func promptPrice(prompt string) float64 {
+	for {
+		input := promptString(prompt, "")
+		if input == "" {
+			return 0
+		}
+
+		value, err := strconv.ParseFloat(input, 64)
+		if err != nil || value < 0 || math.IsInf(value, 0) || math.IsNaN(value) {
+			fmt.Printf("Invalid price '%s': enter a number of dollars, e.g. 0.50\n", input)
+			continue
+		}
+		return value
+	}
+}
+
+// promptOptionalBool is like promptBool but allows the question to be skipped
+// with blank input, in which case nil is returned.
+func promptOptionalBool(prompt string) *bool {
+	requireInteractive(prompt)
+	input := readLine(prompt + " (y/n, blank to skip): ")
+
+	if input == "" {
+		return nil
+	}
+	value := input == "y" || input == "Y"
+	return &value
+}
+
+// getMenuChoice reads a numbered selection between 1 and maxOption. Blank
+// input returns 0 (cancel/back) and anything else out of range returns -1.
+func getMenuChoice(maxOption int) int {
+	requireInteractive("menu choice")
+	input := readLine("\nEnter choice: ")
+
+	if input == "" {
+		return 0
+	}
+	return parseMenuChoice(input, maxOption)
+}
+
+// getTopMenuChoice is getMenuChoice for the top-level menu, where 0 exits the
+// wizard: blank input asks again rather than returning 0, so pressing Enter
+// doesn't quit. Once stdin is exhausted it returns 0.
+func getTopMenuChoice(maxOption int) int {
+	for {
+		requireInteractive("menu choice")
+		input := readLine("\nEnter choice: ")
+
+		if input != "" {
+			return parseMenuChoice(input, maxOption)
+		}
+		if stdinClosed {
+			return 0
+		}
+	}
+}
+
+// parseMenuChoice returns input as a choice between 0 and maxOption, or -1 if
+// it is out of range or not a number.
+func parseMenuChoice(input string, maxOption int) int {
+	choice, err := strconv.Atoi(input)
+	if err != nil {
+		return -1
+	}
+
+	if choice < 0 || choice > maxOption {
+		return -1
+	}
+
+	return choice
+}
+
+// headerNamePattern matches HTTP header names, which must be tokens (RFC
+// 9110).
+var headerNamePattern = regexp.MustCompile("^[!#$%&'*+.^_`|~0-9A-Za-z-]+$")
+
+// promptHeaderName asks for an HTTP header name, asking again until it is
+// valid. Blank input returns "".
+func promptHeaderName(prompt string) string {
+	for {
+		name := promptString(prompt, "")
+		if name == "" || headerNamePattern.MatchString(name) {
+			return name
+		}
+		fmt.Printf("Invalid header name '%s': use letters, digits and - without spaces or colons\n", name)
+	}
+}
+
+// promptHeaderValue asks for an HTTP header value, asking again while it
+// contains control characters such as CR or LF, which would let it inject
+// extra headers. Blank input returns "".
+func promptHeaderValue(prompt string) string {
+	for {
+		value := promptString(prompt, "")
+		if !strings.ContainsFunc(value, func(r rune) bool { return r < ' ' && r != '\t' || r == 0x7f }) {
+			return value
+		}
+		fmt.Println("Invalid header value: it must not contain control characters")
+	}
+}
+
+// resolveSelection maps a numbered selection from a listing of keys to the
+// corresponding key. Anything else is treated as a key as-is.
+func resolveSelection(selection string, keys []string) string {
+	num := 0
+	if _, err := fmt.Sscanf(selection, "%d", &num); err == nil && num > 0 && num <= len(keys) {
+		return keys[num-1]
+	}
+	return selection
+}
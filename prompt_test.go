@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"testing"
+)
+
+// withInput makes prompts read input instead of stdin for the rest of the
+// test. Prompt output is discarded, which also keeps readLine off the
+// terminal line editor.
+func withInput(t *testing.T, input string) {
+	t.Helper()
+	devNull, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scanner, stdout := stdinScanner, os.Stdout
+	stdinScanner = bufio.NewScanner(strings.NewReader(input))
+	os.Stdout = devNull
+	t.Cleanup(func() {
+		stdinScanner, os.Stdout = scanner, stdout
+		devNull.Close()
+	})
+}
+
+func TestGetMenuChoice(t *testing.T) {
+	for _, tc := range []struct {
+		input string
+		want  int
+	}{
+		{"\n", 0},
+		{"0\n", 0},
+		{"2\n", 2},
+		{"3\n", -1},
+		{"-1\n", -1},
+		{"abc\n", -1},
+	} {
+		withInput(t, tc.input)
+		if got := getMenuChoice(2); got != tc.want {
+			t.Errorf("input %q: got %d, want %d", tc.input, got, tc.want)
+		}
+	}
+}
+
+func TestGetTopMenuChoice(t *testing.T) {
+	for _, tc := range []struct {
+		input string
+		want  int
+	}{
+		{"\n\n1\n", 1},
+		{"\n0\n", 0},
+		{"\n9\n", -1},
+		{"\n", 0},
+		{"", 0},
+	} {
+		withInput(t, tc.input)
+		if got := getTopMenuChoice(2); got != tc.want {
+			t.Errorf("input %q: got %d, want %d", tc.input, got, tc.want)
+		}
+	}
+}
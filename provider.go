@@ -1,26 +1,1719 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"text/tabwriter"
+
+	ocfg "github.com/liamwilliams93/opencode-config-wizard/pkg/config"
 )
 
-func addProvider() error {
+func addProvider(args []string) error {
+	fs := flag.NewFlagSet("add", flag.ContinueOnError)
+	noDefaultPrompt := fs.Bool("no-default-prompt", false, "don't offer to change the default or small model")
+	npm := fs.String("npm", "", "AI SDK package for the provider (default: choose from a menu)")
+	force := fs.Bool("force", false, "replace an existing provider without asking")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return fmt.Errorf("usage: add [--no-default-prompt] [--npm <package>] [--force]")
+	}
+	if *npm != "" {
+		if err := validateNPMPackage(*npm); err != nil {
+			return err
+		}
+	}
+
 	configPath, err := getConfigPath()
 	if err != nil {
 		return err
 	}
 
-	configDir := filepath.Dir(configPath)
-	if err := os.MkdirAll(configDir, 0755); err != nil {
-		return err
+	configDir := filepath.Dir(configPath)
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		return err
+	}
+
+	fileExisted := true
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		fileExisted = false
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	if !fileExisted {
+		fmt.Println("Creating new config file...")
+	}
+
+	fmt.Println("\n=== Add Provider ===")
+
+	providerKey := promptString("Provider key (e.g., ollama, custom)", "custom")
+
+	if existing, exists := config.Provider[providerKey]; exists && !*force {
+		fmt.Printf("Provider '%s' already exists with %d model(s)\n", providerKey, len(existing.Models))
+		if promptBool("Add models to it instead?", true) {
+			return appendProviderModels(config, configPath, providerKey, *noDefaultPrompt)
+		}
+		fmt.Printf("Warning: its %d model(s) will be lost\n", len(existing.Models))
+		if !promptBool("Overwrite it?", false) {
+			return errCancelled
+		}
+	}
+
+	if match := ocfg.CaseInsensitiveMatch(config.Provider, providerKey); match != "" {
+		fmt.Printf("Warning: '%s' differs only in case from existing provider '%s'\n", providerKey, match)
+		if !*force && !promptBool("Continue anyway?", false) {
+			return errCancelled
+		}
+	}
+
+	provider := promptProviderDetails(*npm, "Custom Provider", "http://localhost:11434/v1")
+	displayName := provider.Name
+
+	if err := config.AddProvider(providerKey, provider, true); err != nil {
+		return err
+	}
+	suggestEnablingLocalProvider(config, providerKey, provider)
+
+	fmt.Println("\n=== Add Models ===")
+	promptModels(provider.Models)
+
+	if len(provider.Models) > 0 && !*noDefaultPrompt && promptBool("Set as default model?", false) {
+		if modelID := promptNewModelID(provider.Models); modelID != "" {
+			config.Model = fmt.Sprintf("%s/%s", providerKey, modelID)
+		}
+	}
+
+	if len(provider.Models) > 0 && !*noDefaultPrompt && promptBool("Set one of these models as the small model?", false) {
+		if modelID := promptNewModelID(provider.Models); modelID != "" {
+			config.SmallModel = fmt.Sprintf("%s/%s", providerKey, modelID)
+		}
+	}
+
+	printSetupSummary(config, providerKey, provider)
+	if !confirmSave() {
+		return errCancelled
+	}
+
+	if err := saveConfig(config, configPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nConfiguration saved to: %s\n", configPath)
+	fmt.Printf("Added provider: %s with %d model(s)\n", displayName, len(provider.Models))
+	if config.Model != "" {
+		fmt.Printf("Default model: %s\n", config.Model)
+	}
+	if config.SmallModel != "" {
+		fmt.Printf("Small model: %s\n", config.SmallModel)
+	}
+	return nil
+}
+
+// suggestEnablingLocalProvider offers to list a provider served from this
+// machine in enabled_providers, since opencode sometimes filters out
+// providers it doesn't know.
+func suggestEnablingLocalProvider(config *Config, providerKey string, provider Provider) {
+	baseURL, _ := provider.Options["baseURL"].(string)
+	if !isLocalURL(baseURL) || containsString(config.EnabledProviders, providerKey) {
+		return
+	}
+
+	fmt.Printf("\n'%s' runs on this machine; opencode may ignore it unless it is listed in enabled_providers.\n", providerKey)
+	if len(config.EnabledProviders) == 0 {
+		fmt.Println("Note: once enabled_providers is set, only the providers it lists are loaded.")
+	}
+	if promptBool("Add it to enabled_providers?", false) {
+		config.EnabledProviders = dedupeStrings(append(config.EnabledProviders, providerKey))
+	}
+}
+
+// printSetupSummary shows a provider about to be saved together with the
+// default and small model, so mistakes can be caught before saving.
+func printSetupSummary(config *Config, providerKey string, provider Provider) {
+	fmt.Println("\n=== Summary ===")
+	printProvider(providerKey, provider)
+	if config.Model != "" {
+		fmt.Printf("\nDefault model: %s\n", config.Model)
+	}
+	if config.SmallModel != "" {
+		fmt.Printf("Small model: %s\n", config.SmallModel)
+	}
+	if containsString(config.EnabledProviders, providerKey) {
+		fmt.Println("Listed in enabled_providers")
+	}
+}
+
+// appendProviderModels is add's path for a provider that already exists: it
+// keeps the provider's settings and only prompts for more models.
+func appendProviderModels(config *Config, configPath, providerKey string, noDefaultPrompt bool) error {
+	provider := config.Provider[providerKey]
+
+	fmt.Println("\n=== Add Models ===")
+	added := make(map[string]Model)
+	promptModels(added)
+
+	for modelID, model := range added {
+		if _, exists := provider.Models[modelID]; exists {
+			if !promptBool(fmt.Sprintf("Warning: Model '%s' already exists. Overwrite?", modelID), false) {
+				delete(added, modelID)
+				continue
+			}
+		}
+		if err := config.AddModel(providerKey, modelID, model, true); err != nil {
+			return err
+		}
+	}
+
+	if len(added) == 0 {
+		fmt.Println("No models added")
+		return nil
+	}
+
+	if !noDefaultPrompt && promptBool("Set one of the new models as the default model?", false) {
+		if modelID := promptNewModelID(added); modelID != "" {
+			config.Model = ocfg.ModelRef(providerKey, modelID)
+		}
+	}
+
+	fmt.Println("\n=== Summary ===")
+	fmt.Printf("\nModels to add to %s (%s):\n", provider.Name, providerKey)
+	addedIDs := make([]string, 0, len(added))
+	for modelID := range added {
+		addedIDs = append(addedIDs, modelID)
+	}
+	sort.Strings(addedIDs)
+	for _, modelID := range addedIDs {
+		printModelLine(modelID, added[modelID])
+	}
+	if config.Model != "" {
+		fmt.Printf("\nDefault model: %s\n", config.Model)
+	}
+	if !confirmSave() {
+		return errCancelled
+	}
+
+	if err := saveConfig(config, configPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nConfiguration saved to: %s\n", configPath)
+	fmt.Printf("Added %d model(s) to provider: %s\n", len(added), provider.Name)
+	if config.Model != "" {
+		fmt.Printf("Default model: %s\n", config.Model)
+	}
+	return nil
+}
+
+// defaultNPMPackage is the AI SDK package used for OpenAI-compatible
+// providers.
+const defaultNPMPackage = "@ai-sdk/openai-compatible"
+
+// npmPackages are the AI SDK provider packages offered when adding a
+// provider.
+var npmPackages = []struct {
+	name        string
+	description string
+}{
+	{defaultNPMPackage, "OpenAI-compatible APIs (Ollama, LM Studio, vLLM, ...)"},
+	{"@ai-sdk/openai", "OpenAI"},
+	{"@ai-sdk/anthropic", "Anthropic"},
+	{"@ai-sdk/google", "Google Gemini"},
+	{"@ai-sdk/azure", "Azure OpenAI"},
+	{"@openrouter/ai-sdk-provider", "OpenRouter"},
+}
+
+// npmNamePattern matches valid npm package names: lowercase, optionally
+// scoped, and made of URL-safe characters.
+var npmNamePattern = regexp.MustCompile(`^(@[a-z0-9~-][a-z0-9._~-]*/)?[a-z0-9~-][a-z0-9._~-]*$`)
+
+// validateNPMPackage checks that name could be an npm package. It doesn't
+// check that the package exists.
+func validateNPMPackage(name string) error {
+	if len(name) > 214 || !npmNamePattern.MatchString(name) {
+		return fmt.Errorf("%w npm package name '%s'", ocfg.ErrInvalid, name)
+	}
+	return nil
+}
+
+// promptNPMPackage asks which AI SDK package the provider uses, defaulting to
+// current, or to the OpenAI-compatible one if current is empty. Any other
+// package name can be typed in.
+func promptNPMPackage(current string) string {
+	fmt.Println("SDK package:")
+	for i, pkg := range npmPackages {
+		fmt.Printf("  %d. %s - %s\n", i+1, pkg.name, pkg.description)
+	}
+
+	defaultSelection := "1"
+	if current != "" {
+		defaultSelection = current
+	}
+	for {
+		selection := promptString("Select a package or enter an npm package name", defaultSelection)
+		n, err := strconv.Atoi(selection)
+		if err != nil {
+			if err := validateNPMPackage(selection); err != nil {
+				fmt.Println(err)
+				continue
+			}
+			return selection
+		}
+		if n >= 1 && n <= len(npmPackages) {
+			return npmPackages[n-1].name
+		}
+		fmt.Printf("Enter a number between 1 and %d\n", len(npmPackages))
+	}
+}
+
+// promptProviderDetails asks for a provider's SDK package (unless npm is
+// given), display name, base URL, API key and headers, offering the given
+// defaults.
+func promptProviderDetails(npm, defaultName, defaultBaseURL string) Provider {
+	if npm == "" {
+		npm = promptNPMPackage("")
+	}
+	displayName := promptString("Display name", defaultName)
+	baseURL := promptString("Base URL (e.g., http://localhost:11434/v1)", defaultBaseURL)
+	apiKey := promptString("API key (optional)", "")
+
+	provider := Provider{
+		NPM:     npm,
+		Name:    displayName,
+		Options: map[string]interface{}{"baseURL": baseURL},
+		Models:  make(map[string]Model),
+	}
+
+	if apiKey != "" {
+		provider.Options["apiKey"] = apiKey
+	}
+
+	if promptBool("Add custom headers?", false) {
+		headers := make(map[string]string)
+		for {
+			headerName := promptHeaderName("Header name (leave blank to finish)")
+			if headerName == "" {
+				break
+			}
+			headerValue := promptHeaderValue("Header value")
+			if headerValue != "" {
+				headers[headerName] = headerValue
+			}
+			if !promptBool("Add another header?", false) {
+				break
+			}
+		}
+		if len(headers) > 0 {
+			provider.Options["headers"] = headers
+		}
+	}
+
+	return provider
+}
+
+// promptModels asks for models to add to models until a blank ID is entered
+// or the user stops.
+func promptModels(models map[string]Model) {
+	for {
+		modelID := promptString("Model ID (e.g., qwen3-coder)", "")
+		if modelID == "" {
+			break
+		}
+
+		models[modelID] = promptModel(modelID)
+
+		if !promptBool("Add another model?", false) {
+			break
+		}
+	}
+}
+
+// promptModel asks for the display name, upstream ID and optional limits,
+// pricing and capabilities of a model being added under modelID.
+func promptModel(modelID string) Model {
+	modelName := promptString("Display name", modelID)
+	model := Model{Name: modelName}
+
+	// The key is what opencode shows and references; the provider is sent
+	// the ID, which only needs setting when the two differ.
+	if upstreamID := promptString("Upstream model ID sent to the provider", modelID); upstreamID != modelID {
+		model.ID = upstreamID
+	}
+
+	if known, match, ok := lookupModelLimit(modelID); ok {
+		fmt.Printf("Known limits for %s: context %d, output %d\n", match, known.Context, known.Output)
+		if promptBool("Use these limits?", true) {
+			model.Limit = &known
+		}
+	}
+
+	if model.Limit == nil && promptBool("Configure token limits?", false) {
+		contextLimit := promptTokenLimit("Context limit", contextPresets)
+		outputLimit := promptTokenLimit("Output limit", outputPresets)
+
+		if contextLimit > 0 || outputLimit > 0 {
+			model.Limit = &ModelLimit{Context: contextLimit, Output: outputLimit}
+		}
+	}
+
+	if promptBool("Configure pricing?", false) {
+		cost := ModelCost{
+			Input:  promptPrice("Input cost (USD per million tokens, e.g., 0.50)"),
+			Output: promptPrice("Output cost (USD per million tokens, e.g., 1.50)"),
+		}
+		if cost.Input > 0 || cost.Output > 0 {
+			model.Cost = &cost
+		}
+	}
+
+	if promptBool("Configure capabilities?", false) {
+		model.ToolCall = promptOptionalBool("Supports tool calling?")
+		model.Reasoning = promptOptionalBool("Supports reasoning?")
+		model.Attachment = promptOptionalBool("Supports attachments?")
+	}
+
+	return model
+}
+
+// promptTokenLimit shows a menu of token presets and returns the chosen
+// value. A preset is picked by its number or label (e.g. "8k"); a raw token
+// count is only read after choosing Custom, so a small count can't be
+// mistaken for a menu number. Blank input returns 0.
+func promptTokenLimit(prompt string, presets []tokenPreset) int {
+	fmt.Printf("%s:\n", prompt)
+	for i, preset := range presets {
+		fmt.Printf("  %d. %s (%d)\n", i+1, preset.label, preset.tokens)
+	}
+	fmt.Printf("  %d. Custom\n", len(presets)+1)
+
+	for {
+		input := promptString("Select a preset by number or label (blank to skip)", "")
+		if input == "" {
+			return 0
+		}
+
+		if choice, err := strconv.Atoi(input); err == nil {
+			switch {
+			case choice >= 1 && choice <= len(presets):
+				return presets[choice-1].tokens
+			case choice == len(presets)+1:
+				return promptInt(fmt.Sprintf("%s (tokens)", prompt), 0)
+			}
+		}
+		for _, preset := range presets {
+			if strings.EqualFold(input, preset.label) {
+				return preset.tokens
+			}
+		}
+		if strings.EqualFold(input, "custom") {
+			return promptInt(fmt.Sprintf("%s (tokens)", prompt), 0)
+		}
+
+		fmt.Printf("Invalid choice '%s': enter a number from 1 to %d or a label such as %s\n", input, len(presets)+1, presets[0].label)
+	}
+}
+
+// promptNewModelID asks which of models to use, by number or ID. With a
+// single model there is nothing to ask. It returns "" if the answer doesn't
+// match a model.
+func promptNewModelID(models map[string]Model) string {
+	ids := make([]string, 0, len(models))
+	for id := range models {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	if len(ids) == 1 {
+		return ids[0]
+	}
+
+	for i, id := range ids {
+		fmt.Printf("  %d. %s\n", i+1, id)
+	}
+	modelID := resolveSelection(promptString("Enter model number or ID", "1"), ids)
+	if _, exists := models[modelID]; !exists {
+		fmt.Printf("Model '%s' not found\n", modelID)
+		return ""
+	}
+	return modelID
+}
+
+// promptProviderKey lists the configured providers and asks the user to pick
+// one by number or key. It returns "" if the user cancelled.
+func promptProviderKey(config *Config) string {
+	keys := config.ProviderKeys()
+	labels := make([]string, len(keys))
+	for i, key := range keys {
+		provider := config.Provider[key]
+		labels[i] = fmt.Sprintf("%s (%s) - %d model(s)", key, provider.Name, len(provider.Models))
+	}
+
+	if key, ok := tuiSelect("Select a provider", keys, labels); ok {
+		return key
+	}
+
+	fmt.Println("Available providers:")
+	for i, label := range labels {
+		fmt.Printf("  %d. %s\n", i+1, label)
+	}
+
+	selection := promptString("Enter provider number or key", "")
+	if selection == "" {
+		return ""
+	}
+	return resolveSelection(selection, keys)
+}
+
+// sortProviderKeys orders keys, which must be configured providers, by
+// display name, by key, or by model count with the most models first. The
+// sort is stable, so ties keep their order.
+func sortProviderKeys(config *Config, keys []string, by string) error {
+	switch by {
+	case "key":
+		sort.SliceStable(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	case "name":
+		sort.SliceStable(keys, func(i, j int) bool {
+			return strings.ToLower(config.Provider[keys[i]].Name) < strings.ToLower(config.Provider[keys[j]].Name)
+		})
+	case "models":
+		sort.SliceStable(keys, func(i, j int) bool {
+			return len(config.Provider[keys[i]].Models) > len(config.Provider[keys[j]].Models)
+		})
+	default:
+		return fmt.Errorf("%w sort '%s': use name, key or models", ocfg.ErrInvalid, by)
+	}
+	return nil
+}
+
+// sortModelRefs orders refs, which must be configured models, by model ID
+// regardless of provider, or by context limit with the smallest first and
+// models without one last. The sort is stable, so ties keep their order.
+func sortModelRefs(config *Config, refs []string, by string) error {
+	switch by {
+	case "id":
+		sort.SliceStable(refs, func(i, j int) bool {
+			_, a, _ := ocfg.ParseModelRef(refs[i])
+			_, b, _ := ocfg.ParseModelRef(refs[j])
+			return a < b
+		})
+	case "context":
+		contextLimit := func(ref string) int {
+			providerKey, modelID, _ := ocfg.ParseModelRef(ref)
+			if limit := config.Provider[providerKey].Models[modelID].Limit; limit != nil {
+				return limit.Context
+			}
+			return 0
+		}
+		sort.SliceStable(refs, func(i, j int) bool {
+			a, b := contextLimit(refs[i]), contextLimit(refs[j])
+			if a == 0 || b == 0 {
+				return b == 0 && a != 0
+			}
+			return a < b
+		})
+	default:
+		return fmt.Errorf("%w sort '%s': use id or context", ocfg.ErrInvalid, by)
+	}
+	return nil
+}
+
+func listProviders(args []string) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	table := fs.Bool("table", false, "show models in aligned columns")
+	merged := fs.Bool("merged", false, "show the project config layered over the global one")
+	sortBy := fs.String("sort", "key", "order providers by name, key or models")
+	countOnly := fs.Bool("count", false, "print only the number of providers")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if *countOnly && len(args) > 0 {
+		return fmt.Errorf("--count can't be used with a provider")
+	}
+
+	var config *Config
+	if *merged {
+		var globalPath, localPath string
+		config, globalPath, localPath, err = loadMergedConfig()
+		if err != nil {
+			return err
+		}
+		if *countOnly {
+			fmt.Println(len(config.Provider))
+			return nil
+		}
+		fmt.Println("Merged view (project settings override global ones):")
+		fmt.Printf("  Global:  %s\n", describeConfigFile(globalPath))
+		fmt.Printf("  Project: %s\n", describeConfigFile(localPath))
+	} else {
+		configPath, err := getConfigPath()
+		if err != nil {
+			return err
+		}
+		config, err = loadConfig(configPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	if *countOnly {
+		fmt.Println(len(config.Provider))
+		return nil
+	}
+
+	if len(args) > 0 {
+		providerKey := args[0]
+		provider, exists := config.Provider[providerKey]
+		if !exists {
+			return fmt.Errorf("provider '%s' %w", providerKey, ocfg.ErrNotFound)
+		}
+		if *table {
+			printModelTable(config, []string{providerKey})
+		} else {
+			printProvider(providerKey, provider)
+		}
+		return nil
+	}
+
+	if len(config.Provider) == 0 {
+		fmt.Println("No providers configured")
+		return nil
+	}
+
+	keys := config.ProviderKeys()
+	if err := sortProviderKeys(config, keys, *sortBy); err != nil {
+		return err
+	}
+
+	if *table {
+		printModelTable(config, keys)
+		return nil
+	}
+
+	fmt.Println("\n=== Configured Providers ===")
+	for _, key := range keys {
+		printProvider(key, config.Provider[key])
+	}
+
+	if config.Model != "" {
+		fmt.Printf("\nDefault model: %s\n", config.Model)
+	}
+	if config.SmallModel != "" {
+		fmt.Printf("Small model: %s\n", config.SmallModel)
+	}
+	if len(config.EnabledProviders) > 0 {
+		fmt.Printf("Enabled providers: %v\n", config.EnabledProviders)
+	}
+	if len(config.DisabledProviders) > 0 {
+		fmt.Printf("Disabled providers: %v\n", config.DisabledProviders)
+	}
+	if *merged && len(config.MCP) > 0 {
+		names := make([]string, 0, len(config.MCP))
+		for name := range config.MCP {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Printf("MCP servers: %s\n", strings.Join(names, ", "))
+	}
+	return nil
+}
+
+func listModels(args []string) error {
+	fs := flag.NewFlagSet("list-models", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the references as a JSON array")
+	sortBy := fs.String("sort", "", "order models by id or context instead of by reference")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return fmt.Errorf("usage: list-models [--json] [--sort id|context]")
+	}
+
+	configPath, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	refs := config.ModelRefs()
+	if *sortBy != "" {
+		if err := sortModelRefs(config, refs, *sortBy); err != nil {
+			return err
+		}
+	}
+
+	if *asJSON {
+		data, err := json.MarshalIndent(refs, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	for _, ref := range refs {
+		fmt.Println(ref)
+	}
+	return nil
+}
+
+// providerHeaders returns a provider's custom headers. They are a
+// map[string]string when just entered but a map[string]interface{} once read
+// back from the file; both are handled, and non-string values are skipped.
+func providerHeaders(provider Provider) map[string]string {
+	headers := make(map[string]string)
+	switch raw := provider.Options["headers"].(type) {
+	case map[string]string:
+		for name, value := range raw {
+			headers[name] = value
+		}
+	case map[string]interface{}:
+		for name, value := range raw {
+			if s, ok := value.(string); ok {
+				headers[name] = s
+			}
+		}
+	}
+	return headers
+}
+
+// rawProviderHeaders returns a copy of a provider's custom headers with their
+// values as they are, including any that aren't strings.
+func rawProviderHeaders(provider Provider) map[string]interface{} {
+	headers := make(map[string]interface{})
+	switch raw := provider.Options["headers"].(type) {
+	case map[string]string:
+		for name, value := range raw {
+			headers[name] = value
+		}
+	case map[string]interface{}:
+		for name, value := range raw {
+			headers[name] = value
+		}
+	}
+	return headers
+}
+
+func listHeaders(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: list-headers <provider>")
+	}
+	providerKey := args[0]
+
+	configPath, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	provider, exists := config.Provider[providerKey]
+	if !exists {
+		return fmt.Errorf("provider '%s' %w", providerKey, ocfg.ErrNotFound)
+	}
+
+	headers := providerHeaders(provider)
+	if len(headers) == 0 {
+		fmt.Printf("No custom headers for provider '%s'\n", providerKey)
+		return nil
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("%s: %s\n", name, headers[name])
+	}
+	return nil
+}
+
+func renameHeader(args []string) error {
+	if len(args) != 3 {
+		return fmt.Errorf("usage: rename-header <provider> <old> <new>")
+	}
+	providerKey, oldName, newName := args[0], args[1], args[2]
+
+	if !headerNamePattern.MatchString(newName) {
+		return fmt.Errorf("%w header name '%s'", ocfg.ErrInvalid, newName)
+	}
+
+	configPath, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	provider, exists := config.Provider[providerKey]
+	if !exists {
+		return fmt.Errorf("provider '%s' %w", providerKey, ocfg.ErrNotFound)
+	}
+
+	// Work on the raw values so a header that isn't a string, which
+	// providerHeaders would skip, is renamed rather than dropped.
+	headers := rawProviderHeaders(provider)
+	value, exists := headers[oldName]
+	if !exists {
+		return fmt.Errorf("header '%s' %w in provider '%s'", oldName, ocfg.ErrNotFound, providerKey)
+	}
+	if oldName == newName {
+		return nil
+	}
+	if _, exists := headers[newName]; exists {
+		return fmt.Errorf("header '%s' %w in provider '%s'", newName, ocfg.ErrExists, providerKey)
+	}
+
+	delete(headers, oldName)
+	headers[newName] = value
+	provider.Options["headers"] = headers
+
+	if err := saveConfig(config, configPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("Renamed header '%s' to '%s' in provider '%s'\n", oldName, newName, providerKey)
+	return nil
+}
+
+// printModelTable prints every model of the providers with the given keys in
+// aligned columns, in the order of providerKeys and then by model ID.
+func printModelTable(config *Config, providerKeys []string) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROVIDER\tID\tNAME\tCONTEXT\tOUTPUT")
+	for _, key := range providerKeys {
+		models := config.Provider[key].Models
+		modelIDs := make([]string, 0, len(models))
+		for id := range models {
+			modelIDs = append(modelIDs, id)
+		}
+		sort.Strings(modelIDs)
+
+		for _, id := range modelIDs {
+			model := models[id]
+			context, output := "-", "-"
+			if model.Limit != nil && model.Limit.Context > 0 {
+				context = strconv.Itoa(model.Limit.Context)
+			}
+			if model.Limit != nil && model.Limit.Output > 0 {
+				output = strconv.Itoa(model.Limit.Output)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", key, id, model.Name, context, output)
+		}
+	}
+	w.Flush()
+}
+
+func printProvider(key string, provider Provider) {
+	fmt.Printf("\nProvider: %s (%s)\n", provider.Name, key)
+	fmt.Printf("  Base URL: %v\n", provider.Options["baseURL"])
+
+	if headers := providerHeaders(provider); len(headers) > 0 {
+		fmt.Println("  Custom headers:")
+		for k, v := range headers {
+			fmt.Printf("    %s: %s\n", k, v)
+		}
+	}
+
+	for _, key := range sortedOptionKeys(provider.Options) {
+		switch key {
+		case "baseURL", "headers":
+			continue
+		case "apiKey":
+			fmt.Println("  apiKey: (set)")
+		default:
+			fmt.Printf("  %s: %v\n", key, provider.Options[key])
+		}
+	}
+
+	if len(provider.Models) > 0 {
+		fmt.Println("  Models:")
+		for modelID, model := range provider.Models {
+			printModelLine(modelID, model)
+		}
+	} else {
+		fmt.Println("  Models: None")
+	}
+}
+
+// printModelLine prints one model as a list item with its limits, prices and
+// capabilities.
+func printModelLine(modelID string, model Model) {
+	fmt.Printf("    - %s (%s)", model.Name, modelID)
+	if model.ID != "" && model.ID != modelID {
+		fmt.Print(dim(fmt.Sprintf(" [id: %s]", model.ID)))
+	}
+	if model.Limit != nil {
+		if model.Limit.Context > 0 {
+			fmt.Print(dim(fmt.Sprintf(" [context: %d]", model.Limit.Context)))
+		}
+		if model.Limit.Output > 0 {
+			fmt.Print(dim(fmt.Sprintf(" [output: %d]", model.Limit.Output)))
+		}
+	}
+	if model.Cost != nil {
+		if model.Cost.Input > 0 {
+			fmt.Print(dim(fmt.Sprintf(" [input cost: $%g/M]", model.Cost.Input)))
+		}
+		if model.Cost.Output > 0 {
+			fmt.Print(dim(fmt.Sprintf(" [output cost: $%g/M]", model.Cost.Output)))
+		}
+	}
+	printCapability("tool_call", model.ToolCall)
+	printCapability("reasoning", model.Reasoning)
+	printCapability("attachment", model.Attachment)
+	fmt.Println()
+}
+
+func printCapability(name string, value *bool) {
+	if value == nil {
+		return
+	}
+	if *value {
+		fmt.Print(dim(fmt.Sprintf(" [%s: yes]", name)))
+	} else {
+		fmt.Print(dim(fmt.Sprintf(" [%s: no]", name)))
+	}
+}
+
+func deleteProvider(args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("usage: delete [provider]")
+	}
+	var selector string
+	if len(args) == 1 {
+		selector = args[0]
+	}
+
+	configPath, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	if len(config.Provider) == 0 {
+		fmt.Println("No providers to delete")
+		return nil
+	}
+
+	keyToDelete, err := selectProvider(config, selector, "\n=== Delete Provider ===")
+	if err != nil {
+		return err
+	}
+
+	providerName := config.Provider[keyToDelete].Name
+
+	if !promptBool(fmt.Sprintf("Are you sure you want to delete provider '%s'?", providerName), false) {
+		return errCancelled
+	}
+
+	if err := config.DeleteProvider(keyToDelete); err != nil {
+		return err
+	}
+
+	if err := saveConfig(config, configPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("Deleted provider: %s\n", providerName)
+	return nil
+}
+
+func deleteModel(args []string) error {
+	fs := flag.NewFlagSet("delete-model", flag.ContinueOnError)
+	multi := fs.Bool("multi", false, "delete several models from one provider at once")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 1 {
+		return fmt.Errorf("usage: delete-model [provider/model] | delete-model --multi [provider]")
+	}
+	var selector string
+	if len(positional) == 1 {
+		selector = positional[0]
+	}
+
+	configPath, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	if len(config.Provider) == 0 {
+		fmt.Println("No providers configured. Use 'add' command first.")
+		return nil
+	}
+
+	if *multi {
+		return deleteModels(config, configPath, selector)
+	}
+
+	if selector == "" && len(config.ModelRefs()) == 0 {
+		fmt.Println("No models configured")
+		return nil
+	}
+
+	selectedModel, err := selectModel(config, selector, "\n=== Delete Model ===", "Available models:")
+	if err != nil {
+		return err
+	}
+
+	providerKey, modelID, _ := ocfg.ParseModelRef(selectedModel)
+	provider := config.Provider[providerKey]
+	model := provider.Models[modelID]
+
+	if !promptBool(fmt.Sprintf("\nAre you sure you want to delete model '%s' from provider '%s'?", model.Name, provider.Name), false) {
+		return errCancelled
+	}
+
+	cleared, err := config.DeleteModel(providerKey, modelID)
+	if err != nil {
+		return err
+	}
+	printClearedModelRefs(selectedModel, cleared)
+
+	if err := saveConfig(config, configPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("Deleted model: %s\n", model.Name)
+	return nil
+}
+
+// printClearedModelRefs warns that the default or small model was cleared
+// because ref was deleted.
+func printClearedModelRefs(ref string, cleared []string) {
+	for _, field := range cleared {
+		if field == "model" {
+			fmt.Printf("Warning: '%s' was the default model. Default model cleared.\n", ref)
+		} else {
+			fmt.Printf("Warning: '%s' was the small model. Small model cleared.\n", ref)
+		}
+	}
+}
+
+// deleteModels deletes several models from one provider, given by selector or
+// picked from a menu, after a single confirmation.
+func deleteModels(config *Config, configPath, selector string) error {
+	providerKey, err := selectProvider(config, selector, "\n=== Delete Models ===")
+	if err != nil {
+		return err
+	}
+
+	provider := config.Provider[providerKey]
+	if len(provider.Models) == 0 {
+		fmt.Println("No models configured")
+		return nil
+	}
+
+	ids := make([]string, 0, len(provider.Models))
+	for id := range provider.Models {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	fmt.Printf("\nModels in %s:\n", providerKey)
+	for i, id := range ids {
+		fmt.Printf("  %d. %s (%s)\n", i+1, id, provider.Models[id].Name)
+	}
+
+	selection := promptString("Models to delete (numbers or IDs, comma-separated)", "")
+	if selection == "" {
+		return errCancelled
+	}
+
+	var toDelete []string
+	for _, item := range strings.Split(selection, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		id := resolveSelection(item, ids)
+		if _, exists := provider.Models[id]; !exists {
+			return fmt.Errorf("model '%s' %w in provider '%s'", id, ocfg.ErrNotFound, providerKey)
+		}
+		if !containsString(toDelete, id) {
+			toDelete = append(toDelete, id)
+		}
+	}
+	if len(toDelete) == 0 {
+		return errCancelled
+	}
+
+	if !promptBool(fmt.Sprintf("\nDelete %d model(s) from provider '%s' (%s)?", len(toDelete), provider.Name, strings.Join(toDelete, ", ")), false) {
+		return errCancelled
+	}
+
+	for _, id := range toDelete {
+		cleared, err := config.DeleteModel(providerKey, id)
+		if err != nil {
+			return err
+		}
+		printClearedModelRefs(ocfg.ModelRef(providerKey, id), cleared)
+	}
+
+	if err := saveConfig(config, configPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("Deleted %d model(s)\n", len(toDelete))
+	return nil
+}
+
+// promptModelRef lists every configured model by number and asks for one by
+// number or provider/model reference, asking again until the answer names a
+// configured model. It returns "" if the user cancelled.
+func promptModelRef(config *Config) string {
+	models := config.ModelRefs()
+	labels := make([]string, len(models))
+	for i, modelRef := range models {
+		providerKey, modelID, _ := ocfg.ParseModelRef(modelRef)
+		labels[i] = fmt.Sprintf("%s (%s)", modelRef, config.Provider[providerKey].Models[modelID].Name)
+	}
+
+	if modelRef, ok := tuiSelect("Select a model", models, labels); ok {
+		return modelRef
+	}
+
+	for i, label := range labels {
+		fmt.Printf("  %d. %s\n", i+1, label)
+	}
+
+	for {
+		selection := promptString("Enter model number or provider/model", "")
+		if selection == "" {
+			return ""
+		}
+
+		if n, err := strconv.Atoi(selection); err == nil && (n < 1 || n > len(models)) {
+			fmt.Printf("Enter a number between 1 and %d\n", len(models))
+			continue
+		}
+
+		modelRef := resolveSelection(selection, models)
+		if err := config.ValidateModelRef(modelRef); err != nil {
+			fmt.Printf("%v\n", err)
+			continue
+		}
+		return modelRef
+	}
+}
+
+func setDefaultModel(args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("usage: set-default [provider/model]")
+	}
+
+	configPath, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 1 {
+		if err := config.SetDefaultModel(args[0]); err != nil {
+			return err
+		}
+		if err := saveConfig(config, configPath); err != nil {
+			return err
+		}
+		fmt.Printf("Default model set to: %s\n", config.Model)
+		return nil
+	}
+
+	if len(config.Provider) == 0 {
+		fmt.Println("No providers configured. Use 'add' command first.")
+		return nil
+	}
+
+	selectedModel, err := selectModel(config, "", "\n=== Set Default Model ===", "Available models:")
+	if err != nil {
+		return err
+	}
+	config.Model = selectedModel
+
+	if err := saveConfig(config, configPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("Default model set to: %s\n", selectedModel)
+	return nil
+}
+
+// defaultModels is what which-default reports.
+type defaultModels struct {
+	Model      string `json:"model"`
+	SmallModel string `json:"small_model"`
+	Valid      bool   `json:"valid"`
+}
+
+func whichDefault(args []string) error {
+	fs := flag.NewFlagSet("which-default", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the models as a JSON object")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return fmt.Errorf("usage: which-default [--json]")
+	}
+
+	configPath, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	result := defaultModels{Model: config.Model, SmallModel: config.SmallModel, Valid: true}
+	var dangling []string
+	problems := make(map[string]error)
+	for _, ref := range []string{config.Model, config.SmallModel} {
+		if ref == "" {
+			continue
+		}
+		if err := config.ValidateModelRef(ref); err != nil {
+			result.Valid = false
+			dangling = append(dangling, ref)
+			problems[ref] = err
+		}
+	}
+
+	if *asJSON {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	} else {
+		for _, field := range []struct{ label, ref string }{
+			{"Default model", config.Model},
+			{"Small model", config.SmallModel},
+		} {
+			switch {
+			case field.ref == "":
+				fmt.Printf("%s: %s\n", field.label, dim("(not set)"))
+			case problems[field.ref] != nil:
+				fmt.Printf("%s: %s %s\n", field.label, field.ref, red("("+problems[field.ref].Error()+")"))
+			default:
+				fmt.Printf("%s: %s\n", field.label, field.ref)
+			}
+		}
+	}
+
+	if len(dangling) > 0 {
+		return fmt.Errorf("model reference(s) %w: %s", ocfg.ErrNotFound, strings.Join(dedupeStrings(dangling), ", "))
+	}
+	return nil
+}
+
+func addModel(args []string) error {
+	fs := flag.NewFlagSet("add-model", flag.ContinueOnError)
+	providerFlag := fs.String("provider", "", "provider to add the model to")
+	idFlag := fs.String("id", "", "model ID; adds the model without prompting")
+	nameFlag := fs.String("name", "", "display name (default: the model ID)")
+	contextFlag := fs.Int("context", 0, "context window limit in tokens")
+	outputFlag := fs.Int("output", 0, "output limit in tokens")
+	force := fs.Bool("force", false, "replace an existing model without asking")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return fmt.Errorf("usage: add-model [--force] [--provider <key> --id <model> [--name <name>] [--context <tokens>] [--output <tokens>]]")
+	}
+
+	configPath, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	if *idFlag != "" {
+		return addModelFromFlags(config, configPath, *providerFlag, *idFlag, *nameFlag, *contextFlag, *outputFlag, *force)
+	}
+
+	if len(config.Provider) == 0 {
+		fmt.Println("No providers configured. Use 'add' command first.")
+		return nil
+	}
+
+	fmt.Println("\n=== Add Model to Existing Provider ===")
+
+	providerKey := promptProviderKey(config)
+	if providerKey == "" {
+		return errCancelled
+	}
+
+	if _, exists := config.Provider[providerKey]; !exists {
+		return fmt.Errorf("provider '%s' %w", providerKey, ocfg.ErrNotFound)
+	}
+
+	provider := config.Provider[providerKey]
+	fmt.Printf("\nAdding model to provider: %s (%s)\n", provider.Name, providerKey)
+
+	modelID := promptString("Model ID (e.g., qwen3-coder)", "")
+	if modelID == "" {
+		return errCancelled
+	}
+
+	model := promptModel(modelID)
+
+	if _, exists := provider.Models[modelID]; exists && !*force {
+		if !promptBool(fmt.Sprintf("\nWarning: Model '%s' already exists. Overwrite?", modelID), false) {
+			return errCancelled
+		}
+	}
+
+	provider.Models[modelID] = model
+
+	if promptBool("Set as default model?", false) {
+		config.Model = fmt.Sprintf("%s/%s", providerKey, modelID)
+	}
+
+	if err := saveConfig(config, configPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nModel '%s' added to provider '%s'\n", model.Name, provider.Name)
+	if config.Model == fmt.Sprintf("%s/%s", providerKey, modelID) {
+		fmt.Printf("Default model: %s\n", config.Model)
+	}
+	return nil
+}
+
+// addModelFromFlags adds a model built from add-model's flags without any
+// prompts. An existing model is only replaced if force is set.
+func addModelFromFlags(config *Config, configPath, providerKey, modelID, name string, context, output int, force bool) error {
+	if providerKey == "" {
+		return fmt.Errorf("--provider is required when adding a model with --id")
+	}
+	if context < 0 || output < 0 {
+		return fmt.Errorf("--context and --output must be positive")
+	}
+
+	if name == "" {
+		name = modelID
+	}
+	model := Model{Name: name}
+	if context > 0 || output > 0 {
+		model.Limit = &ModelLimit{Context: context, Output: output}
+	}
+
+	if err := config.AddModel(providerKey, modelID, model, force); err != nil {
+		return err
+	}
+	provider := config.Provider[providerKey]
+
+	if err := saveConfig(config, configPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("Model '%s' added to provider '%s'\n", model.Name, provider.Name)
+	return nil
+}
+
+func sortedOptionKeys(options map[string]interface{}) []string {
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// stringOptions are provider options that always hold strings, so a value
+// such as a numeric API key isn't stored as a number.
+var stringOptions = map[string]bool{"apiKey": true, "baseURL": true}
+
+// parseOptionValue converts a command-line value for the option key to the
+// JSON type it most likely represents: an integer, a float, a boolean, or
+// else a string. Options in stringOptions are always strings. Numbers JSON
+// can't represent (NaN, infinities, and values that overflow a float64) are
+// rejected.
+func parseOptionValue(key, value string) (interface{}, error) {
+	if stringOptions[key] {
+		return value, nil
+	}
+	if i, err := strconv.Atoi(value); err == nil {
+		return i, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+		return f, nil
+	}
+	if err == nil || errors.Is(err, strconv.ErrRange) {
+		return nil, fmt.Errorf("%w value '%s' for option '%s': not a finite number (use --string to store it as text)", ocfg.ErrInvalid, value, key)
+	}
+	if value == "true" || value == "false" {
+		return value == "true", nil
+	}
+	return value, nil
+}
+
+func setProviderOption(args []string) error {
+	fs := flag.NewFlagSet("set-option", flag.ContinueOnError)
+	asString := fs.Bool("string", false, "store the value as a string, without converting numbers and booleans")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 0 && len(args) != 3 {
+		return fmt.Errorf("usage: set-option [--string] <provider> <key> <value>")
+	}
+
+	configPath, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	var providerKey, optionKey, optionValue string
+	if len(args) == 3 {
+		providerKey, optionKey, optionValue = args[0], args[1], args[2]
+		if _, exists := config.Provider[providerKey]; !exists {
+			return fmt.Errorf("provider '%s' %w", providerKey, ocfg.ErrNotFound)
+		}
+	} else {
+		if len(config.Provider) == 0 {
+			fmt.Println("No providers configured. Use 'add' command first.")
+			return nil
+		}
+
+		fmt.Println("\n=== Set Provider Option ===")
+
+		providerKey = promptProviderKey(config)
+		if providerKey == "" {
+			return errCancelled
+		}
+		if _, exists := config.Provider[providerKey]; !exists {
+			return fmt.Errorf("provider '%s' %w", providerKey, ocfg.ErrNotFound)
+		}
+
+		optionKey = promptString("Option name (e.g., temperature, maxRetries)", "")
+		if optionKey == "" {
+			return errCancelled
+		}
+		optionValue = promptString("Option value", "")
+		if optionValue == "" {
+			return errCancelled
+		}
+	}
+
+	if optionKey == "headers" {
+		return fmt.Errorf("headers cannot be set with set-option")
+	}
+
+	provider := config.Provider[providerKey]
+	if provider.Options == nil {
+		provider.Options = make(map[string]interface{})
+	}
+	var value interface{} = optionValue
+	if !*asString {
+		if value, err = parseOptionValue(optionKey, optionValue); err != nil {
+			return err
+		}
+	}
+	provider.Options[optionKey] = value
+	config.Provider[providerKey] = provider
+
+	if err := saveConfig(config, configPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("Set option '%s' on provider '%s' to %v\n", optionKey, providerKey, value)
+	return nil
+}
+
+func deleteProviderOption(args []string) error {
+	if len(args) != 0 && len(args) != 2 {
+		return fmt.Errorf("usage: delete-option <provider> <key>")
+	}
+
+	configPath, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	var providerKey, optionKey string
+	if len(args) == 2 {
+		providerKey, optionKey = args[0], args[1]
+		provider, exists := config.Provider[providerKey]
+		if !exists {
+			return fmt.Errorf("provider '%s' %w", providerKey, ocfg.ErrNotFound)
+		}
+		if _, exists := provider.Options[optionKey]; !exists {
+			return fmt.Errorf("option '%s' not set on provider '%s'", optionKey, providerKey)
+		}
+	} else {
+		if len(config.Provider) == 0 {
+			fmt.Println("No providers configured. Use 'add' command first.")
+			return nil
+		}
+
+		fmt.Println("\n=== Delete Provider Option ===")
+
+		providerKey = promptProviderKey(config)
+		if providerKey == "" {
+			return errCancelled
+		}
+		provider, exists := config.Provider[providerKey]
+		if !exists {
+			return fmt.Errorf("provider '%s' %w", providerKey, ocfg.ErrNotFound)
+		}
+
+		keys := sortedOptionKeys(provider.Options)
+		if len(keys) == 0 {
+			fmt.Println("No options set on this provider")
+			return nil
+		}
+
+		fmt.Println("Options:")
+		for i, key := range keys {
+			fmt.Printf("  %d. %s\n", i+1, key)
+		}
+
+		choice := getMenuChoice(len(keys))
+		if choice == -1 {
+			fmt.Println("Invalid choice")
+			return nil
+		}
+		if choice == 0 {
+			return errCancelled
+		}
+		optionKey = keys[choice-1]
+
+		if !promptBool(fmt.Sprintf("Are you sure you want to delete option '%s'?", optionKey), false) {
+			return errCancelled
+		}
+	}
+
+	delete(config.Provider[providerKey].Options, optionKey)
+
+	if err := saveConfig(config, configPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("Deleted option '%s' from provider '%s'\n", optionKey, providerKey)
+	return nil
+}
+
+func setProviderNPM(args []string) error {
+	if len(args) > 2 {
+		return fmt.Errorf("usage: set-npm [provider] [package]")
+	}
+
+	configPath, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	var selector string
+	if len(args) > 0 {
+		selector = args[0]
+	} else if len(config.Provider) == 0 {
+		fmt.Println("No providers configured. Use 'add' command first.")
+		return nil
+	}
+
+	providerKey, err := selectProvider(config, selector, "\n=== Set SDK Package ===")
+	if err != nil {
+		return err
+	}
+	provider := config.Provider[providerKey]
+
+	var npm string
+	if len(args) == 2 {
+		npm = args[1]
+		if err := validateNPMPackage(npm); err != nil {
+			return err
+		}
+	} else {
+		npm = promptNPMPackage(provider.NPM)
+	}
+	if npm == provider.NPM {
+		fmt.Printf("Provider '%s' already uses %s\n", providerKey, npm)
+		return nil
+	}
+
+	previous := provider.NPM
+	provider.NPM = npm
+	config.Provider[providerKey] = provider
+
+	if err := saveConfig(config, configPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("SDK package for '%s' changed from %s to %s\n", providerKey, previous, npm)
+	return nil
+}
+
+// modelRefProvider returns the provider key of a "provider/model" reference.
+func modelRefProvider(ref string) string {
+	providerKey, _, _ := strings.Cut(ref, "/")
+	return providerKey
+}
+
+func purgeDisabledProviders(args []string) error {
+	configPath, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	purge := disabledConfiguredProviders(config)
+	if len(purge) == 0 {
+		fmt.Println("No disabled providers to purge")
+		return nil
+	}
+
+	fmt.Println("\n=== Purge Disabled Providers ===")
+	fmt.Println("The following providers will be deleted:")
+	for _, key := range purge {
+		fmt.Printf("  - %s (%s) - %d model(s)\n", key, config.Provider[key].Name, len(config.Provider[key].Models))
+	}
+
+	if !promptBool("Are you sure you want to continue?", false) {
+		return errCancelled
+	}
+
+	purgeProviders(config, purge)
+
+	if config.Model != "" {
+		if _, exists := config.Provider[modelRefProvider(config.Model)]; !exists {
+			fmt.Printf("Warning: Default model '%s' was purged. Default model cleared.\n", config.Model)
+			config.Model = ""
+		}
+	}
+	if config.SmallModel != "" {
+		if _, exists := config.Provider[modelRefProvider(config.SmallModel)]; !exists {
+			fmt.Printf("Warning: Small model '%s' was purged. Small model cleared.\n", config.SmallModel)
+			config.SmallModel = ""
+		}
+	}
+
+	if err := saveConfig(config, configPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("Purged %d disabled provider(s)\n", len(purge))
+	return nil
+}
+
+// disabledConfiguredProviders returns the keys in disabled_providers that
+// have a provider entry in config, in list order.
+func disabledConfiguredProviders(config *Config) []string {
+	var keys []string
+	for _, key := range config.DisabledProviders {
+		if _, exists := config.Provider[key]; exists && !containsString(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// purgeProviders deletes each of keys from config along with its
+// disabled_providers entry. Other entries, such as disabled built-in
+// providers that have no entry in config, are left alone.
+func purgeProviders(config *Config, keys []string) {
+	for _, key := range keys {
+		delete(config.Provider, key)
+		config.DisabledProviders = removeString(config.DisabledProviders, key)
+	}
+}
+
+// isEmptyMCPServer reports whether server is disabled and has nothing to run
+// or connect to, which is what an abandoned add-mcp leaves behind.
+func isEmptyMCPServer(server MCPServer) bool {
+	disabled := server.Enabled != nil && !*server.Enabled
+	return disabled && len(server.Command) == 0 && server.URL == ""
+}
+
+// clearDanglingModelRefs clears the default and small model if they refer to
+// a model that no longer exists.
+func clearDanglingModelRefs(config *Config) {
+	if config.Model != "" && config.ValidateModelRef(config.Model) != nil {
+		fmt.Printf("Warning: Default model '%s' no longer exists. Default model cleared.\n", config.Model)
+		config.Model = ""
+	}
+	if config.SmallModel != "" && config.ValidateModelRef(config.SmallModel) != nil {
+		fmt.Printf("Warning: Small model '%s' no longer exists. Small model cleared.\n", config.SmallModel)
+		config.SmallModel = ""
+	}
+}
+
+func pruneEmpty(args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("usage: prune-empty")
 	}
 
-	fileExisted := true
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		fileExisted = false
+	configPath, err := getConfigPath()
+	if err != nil {
+		return err
 	}
 
 	config, err := loadConfig(configPath)
@@ -28,107 +1721,174 @@ func addProvider() error {
 		return err
 	}
 
-	if !fileExisted {
-		fmt.Println("Creating new config file...")
+	providers := config.EmptyProviders()
+	var servers []string
+	for name, server := range config.MCP {
+		if isEmptyMCPServer(server) {
+			servers = append(servers, name)
+		}
 	}
+	sort.Strings(servers)
 
-	fmt.Println("\n=== Add OpenAI-Compatible Provider ===")
-
-	providerKey := promptString("Provider key (e.g., ollama, custom)", "custom")
-	displayName := promptString("Display name", "Custom Provider")
-	baseURL := promptString("Base URL (e.g., http://localhost:11434/v1)", "http://localhost:11434/v1")
-	apiKey := promptString("API key (optional)", "")
-
-	provider := Provider{
-		NPM:     "@ai-sdk/openai-compatible",
-		Name:    displayName,
-		Options: map[string]interface{}{"baseURL": baseURL},
-		Models:  make(map[string]Model),
+	if len(providers) == 0 && len(servers) == 0 {
+		fmt.Println("Nothing to prune")
+		return nil
 	}
 
-	if apiKey != "" {
-		provider.Options["apiKey"] = apiKey
+	fmt.Println("\n=== Prune Empty Entries ===")
+	if len(providers) > 0 {
+		fmt.Println("Providers with no models:")
+		for _, key := range providers {
+			fmt.Printf("  - %s (%s)\n", key, config.Provider[key].Name)
+		}
+		if promptBool(fmt.Sprintf("Delete these %d provider(s)?", len(providers)), false) {
+			for _, key := range providers {
+				delete(config.Provider, key)
+			}
+		} else {
+			providers = nil
+		}
 	}
 
-	if promptBool("Add custom headers?", false) {
-		headers := make(map[string]string)
-		for {
-			headerName := promptString("Header name (leave blank to finish)", "")
-			if headerName == "" {
-				break
-			}
-			headerValue := promptString("Header value", "")
-			if headerValue != "" {
-				headers[headerName] = headerValue
-			}
-			if !promptBool("Add another header?", false) {
-				break
-			}
+	if len(servers) > 0 {
+		fmt.Println("Disabled MCP servers with no command or URL:")
+		for _, name := range servers {
+			fmt.Printf("  - %s\n", name)
 		}
-		if len(headers) > 0 {
-			provider.Options["headers"] = headers
+		if promptBool(fmt.Sprintf("Delete these %d MCP server(s)?", len(servers)), false) {
+			for _, name := range servers {
+				delete(config.MCP, name)
+			}
+		} else {
+			servers = nil
 		}
 	}
 
-	config.Provider[providerKey] = provider
+	if len(providers) == 0 && len(servers) == 0 {
+		return errCancelled
+	}
 
-	fmt.Println("\n=== Add Models ===")
-	for {
-		modelID := promptString("Model ID (e.g., qwen3-coder)", "")
-		if modelID == "" {
-			break
-		}
+	clearDanglingModelRefs(config)
 
-		modelName := promptString("Display name", modelID)
-		model := Model{Name: modelName}
+	if err := saveConfig(config, configPath); err != nil {
+		return err
+	}
 
-		if promptBool("Configure token limits?", false) {
-			contextLimit := promptString("Context limit (tokens, e.g., 128000)", "")
-			outputLimit := promptString("Output limit (tokens, e.g., 65536)", "")
+	fmt.Printf("Pruned %d provider(s) and %d MCP server(s)\n", len(providers), len(servers))
+	return nil
+}
 
-			if contextLimit != "" || outputLimit != "" {
-				limit := &ModelLimit{}
-				if contextLimit != "" {
-					fmt.Sscanf(contextLimit, "%d", &limit.Context)
-				}
-				if outputLimit != "" {
-					fmt.Sscanf(outputLimit, "%d", &limit.Output)
-				}
-				model.Limit = limit
-			}
+// dedupeStrings returns list without repeated entries, keeping the first
+// occurrence of each.
+func dedupeStrings(list []string) []string {
+	seen := make(map[string]bool, len(list))
+	var out []string
+	for _, item := range list {
+		if !seen[item] {
+			seen[item] = true
+			out = append(out, item)
 		}
+	}
+	return out
+}
 
-		provider.Models[modelID] = model
+// removeString returns list without any entries equal to s.
+func removeString(list []string, s string) []string {
+	var out []string
+	for _, item := range list {
+		if item != s {
+			out = append(out, item)
+		}
+	}
+	return out
+}
 
-		if !promptBool("Add another model?", false) {
-			break
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
 		}
 	}
+	return false
+}
+
+func toggleProvider(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: toggle-provider <key>")
+	}
+	providerKey := args[0]
 
-	if len(provider.Models) > 0 && promptBool("Set as default model?", false) {
-		config.Model = fmt.Sprintf("%s/%s", providerKey, getFirstModelID(provider.Models))
+	configPath, err := getConfigPath()
+	if err != nil {
+		return err
 	}
 
-	if err := saveConfig(config, configPath); err != nil {
+	config, err := loadConfig(configPath)
+	if err != nil {
 		return err
 	}
 
-	fmt.Printf("\nConfiguration saved to: %s\n", configPath)
-	fmt.Printf("Added provider: %s with %d model(s)\n", displayName, len(provider.Models))
-	if config.Model != "" {
-		fmt.Printf("Default model: %s\n", config.Model)
+	// Built-in providers such as openai can be disabled without being
+	// configured here, so an unknown key is only worth a note.
+	if _, exists := config.Provider[providerKey]; !exists {
+		fmt.Printf("Note: '%s' is not a configured provider\n", providerKey)
+	}
+
+	var state string
+	if containsString(config.DisabledProviders, providerKey) {
+		config.DisabledProviders = removeString(config.DisabledProviders, providerKey)
+		// With an enabled_providers list only the listed providers are
+		// loaded, so re-enabling has to add it there too.
+		if len(config.EnabledProviders) > 0 {
+			config.EnabledProviders = append(config.EnabledProviders, providerKey)
+		}
+		state = "enabled"
+	} else {
+		config.EnabledProviders = removeString(config.EnabledProviders, providerKey)
+		config.DisabledProviders = append(config.DisabledProviders, providerKey)
+		state = "disabled"
+	}
+	config.EnabledProviders = dedupeStrings(config.EnabledProviders)
+	config.DisabledProviders = dedupeStrings(config.DisabledProviders)
+
+	if err := saveConfig(config, configPath); err != nil {
+		return err
 	}
+
+	fmt.Printf("Provider '%s' is now %s\n", providerKey, state)
 	return nil
 }
 
-func getFirstModelID(models map[string]Model) string {
-	for id := range models {
-		return id
+// effectiveProviders resolves which providers opencode will load: only those
+// in enabled_providers if it is set, otherwise every configured provider, and
+// in either case minus disabled_providers, which takes priority. It also
+// returns providers listed in both, which are conflicts.
+func effectiveProviders(config *Config) (enabled, conflicts []string) {
+	candidates := config.EnabledProviders
+	if len(candidates) == 0 {
+		candidates = config.ProviderKeys()
+	}
+
+	for _, key := range dedupeStrings(candidates) {
+		if containsString(config.DisabledProviders, key) {
+			if containsString(config.EnabledProviders, key) {
+				conflicts = append(conflicts, key)
+			}
+			continue
+		}
+		enabled = append(enabled, key)
 	}
-	return ""
+
+	sort.Strings(enabled)
+	sort.Strings(conflicts)
+	return enabled, conflicts
 }
 
-func listProviders() error {
+func showEffectiveProviders(args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("usage: effective")
+	}
+
 	configPath, err := getConfigPath()
 	if err != nil {
 		return err
@@ -139,58 +1899,59 @@ func listProviders() error {
 		return err
 	}
 
-	if len(config.Provider) == 0 {
-		fmt.Println("No providers configured")
-		return nil
-	}
+	enabled, conflicts := effectiveProviders(config)
 
-	fmt.Println("\n=== Configured Providers ===")
-	for key, provider := range config.Provider {
-		fmt.Printf("\nProvider: %s (%s)\n", provider.Name, key)
-		fmt.Printf("  Base URL: %v\n", provider.Options["baseURL"])
-
-		if headers, ok := provider.Options["headers"].(map[string]interface{}); ok && len(headers) > 0 {
-			fmt.Println("  Custom headers:")
-			for k, v := range headers {
-				fmt.Printf("    %s: %v\n", k, v)
-			}
-		}
+	fmt.Println("\n=== Effective Providers ===")
+	if len(config.EnabledProviders) > 0 {
+		fmt.Println("Only providers in enabled_providers are loaded.")
+	} else {
+		fmt.Println("All configured providers are loaded except those in disabled_providers.")
+	}
+	fmt.Println()
 
-		if len(provider.Models) > 0 {
-			fmt.Println("  Models:")
-			for modelID, model := range provider.Models {
-				fmt.Printf("    - %s (%s)", model.Name, modelID)
-				if model.Limit != nil {
-					if model.Limit.Context > 0 {
-						fmt.Printf(" [context: %d]", model.Limit.Context)
-					}
-					if model.Limit.Output > 0 {
-						fmt.Printf(" [output: %d]", model.Limit.Output)
-					}
-				}
-				fmt.Println()
-			}
+	if len(enabled) == 0 {
+		fmt.Println("No providers are enabled")
+	}
+	for _, key := range enabled {
+		if provider, exists := config.Provider[key]; exists {
+			fmt.Printf("  %s %s (%s)\n", green("+"), key, provider.Name)
 		} else {
-			fmt.Println("  Models: None")
+			fmt.Printf("  %s %s %s\n", green("+"), key, dim("(built-in or not configured)"))
 		}
 	}
 
-	if config.Model != "" {
-		fmt.Printf("\nDefault model: %s\n", config.Model)
+	for _, key := range conflicts {
+		fmt.Printf("  %s %s %s\n", red("!"), key, red("conflict: in both enabled_providers and disabled_providers; disabled wins"))
 	}
-	if config.SmallModel != "" {
-		fmt.Printf("Small model: %s\n", config.SmallModel)
+	return nil
+}
+
+// cloneModel returns a deep copy of model so that edits to the copy never
+// affect the original's limits, cost or capabilities.
+func cloneModel(model Model) Model {
+	clone := model
+	if model.Limit != nil {
+		limit := *model.Limit
+		clone.Limit = &limit
 	}
-	if len(config.EnabledProviders) > 0 {
-		fmt.Printf("Enabled providers: %v\n", config.EnabledProviders)
+	if model.Cost != nil {
+		cost := *model.Cost
+		clone.Cost = &cost
 	}
-	if len(config.DisabledProviders) > 0 {
-		fmt.Printf("Disabled providers: %v\n", config.DisabledProviders)
+	for _, capability := range []**bool{&clone.ToolCall, &clone.Reasoning, &clone.Attachment} {
+		if *capability != nil {
+			value := **capability
+			*capability = &value
+		}
 	}
-	return nil
+	return clone
 }
 
-func deleteProvider() error {
+func copyProviderModels(args []string) error {
+	if len(args) != 0 && len(args) != 2 {
+		return fmt.Errorf("usage: copy-provider-models <source> <destination>")
+	}
+
 	configPath, err := getConfigPath()
 	if err != nil {
 		return err
@@ -201,52 +1962,66 @@ func deleteProvider() error {
 		return err
 	}
 
-	if len(config.Provider) == 0 {
-		fmt.Println("No providers to delete")
-		return nil
-	}
-
-	fmt.Println("\n=== Delete Provider ===")
-	fmt.Println("Available providers:")
+	var sourceKey, destKey string
+	if len(args) == 2 {
+		sourceKey, destKey = args[0], args[1]
+	} else {
+		if len(config.Provider) < 2 {
+			fmt.Println("At least two providers are needed to copy models")
+			return nil
+		}
 
-	keys := make([]string, 0, len(config.Provider))
-	i := 1
-	for key, provider := range config.Provider {
-		fmt.Printf("  %d. %s (%s)\n", i, key, provider.Name)
-		keys = append(keys, key)
-		i++
+		fmt.Println("\n=== Copy Provider Models ===")
 	}
 
-	choice := getMenuChoice(len(keys))
-	if choice == -1 {
-		fmt.Println("Invalid choice")
-		return nil
+	sourceKey, err = selectProvider(config, sourceKey, "Copy models from:")
+	if err != nil {
+		return err
 	}
-	if choice == 0 {
-		fmt.Println("Cancelled")
-		return nil
+	destKey, err = selectProvider(config, destKey, "\nCopy models to:")
+	if err != nil {
+		return err
 	}
-
-	keyToDelete := keys[choice-1]
-
-	providerName := config.Provider[keyToDelete].Name
-
-	if !promptBool(fmt.Sprintf("Are you sure you want to delete provider '%s'?", providerName), false) {
-		fmt.Println("Cancelled")
-		return nil
+	source := config.Provider[sourceKey]
+	if sourceKey == destKey {
+		return fmt.Errorf("source and destination are both '%s'", sourceKey)
 	}
 
-	delete(config.Provider, keyToDelete)
+	modelIDs := make([]string, 0, len(source.Models))
+	for modelID := range source.Models {
+		modelIDs = append(modelIDs, modelID)
+	}
+	sort.Strings(modelIDs)
+
+	copied, skipped := 0, 0
+	for _, modelID := range modelIDs {
+		if _, exists := config.Provider[destKey].Models[modelID]; exists {
+			if !promptBool(fmt.Sprintf("Model '%s' already exists in provider '%s'. Overwrite?", modelID, destKey), false) {
+				skipped++
+				continue
+			}
+		}
+		if err := config.AddModel(destKey, modelID, cloneModel(source.Models[modelID]), true); err != nil {
+			return err
+		}
+		copied++
+	}
 
-	if err := saveConfig(config, configPath); err != nil {
-		return err
+	if copied > 0 {
+		if err := saveConfig(config, configPath); err != nil {
+			return err
+		}
 	}
 
-	fmt.Printf("Deleted provider: %s\n", providerName)
+	fmt.Printf("Copied %d model(s) from '%s' to '%s', skipped %d\n", copied, sourceKey, destKey, skipped)
 	return nil
 }
 
-func deleteModel() error {
+func moveModel(args []string) error {
+	if len(args) > 2 {
+		return fmt.Errorf("usage: move-model [provider/model] [destination-provider]")
+	}
+
 	configPath, err := getConfigPath()
 	if err != nil {
 		return err
@@ -257,84 +2032,72 @@ func deleteModel() error {
 		return err
 	}
 
-	if len(config.Provider) == 0 {
-		fmt.Println("No providers configured. Use 'add' command first.")
+	if len(config.Provider) < 2 {
+		fmt.Println("At least two providers are needed to move a model")
 		return nil
 	}
 
-	fmt.Println("\n=== Delete Model ===")
-	fmt.Println("Available models:")
-
-	models := []string{}
-	i := 1
-	for providerKey, provider := range config.Provider {
-		for modelID := range provider.Models {
-			modelRef := fmt.Sprintf("%s/%s", providerKey, modelID)
-			models = append(models, modelRef)
-			fmt.Printf("  %d. %s (%s)\n", i, modelRef, provider.Models[modelID].Name)
-			i++
-		}
+	var sourceSelector, destSelector string
+	if len(args) > 0 {
+		sourceSelector = args[0]
 	}
-
-	if len(models) == 0 {
-		fmt.Println("No models configured")
-		return nil
+	if len(args) > 1 {
+		destSelector = args[1]
 	}
 
-	choice := getMenuChoice(len(models))
-	if choice == -1 {
-		fmt.Println("Invalid choice")
-		return nil
+	sourceRef, err := selectModel(config, sourceSelector, "\n=== Move Model ===", "Model to move:")
+	if err != nil {
+		return err
 	}
-	if choice == 0 {
-		fmt.Println("Cancelled")
-		return nil
+	destKey, err := selectProvider(config, destSelector, "\nDestination provider:")
+	if err != nil {
+		return err
 	}
 
-	selectedModel := models[choice-1]
-
-	parts := strings.Split(selectedModel, "/")
-	if len(parts) != 2 {
-		fmt.Printf("Invalid model reference: %s\n", selectedModel)
-		return nil
+	sourceKey, modelID, _ := strings.Cut(sourceRef, "/")
+	if destKey == sourceKey {
+		return fmt.Errorf("model is already in provider '%s'", destKey)
 	}
+	dest := config.Provider[destKey]
 
-	providerKey := parts[0]
-	modelID := parts[1]
-
-	provider, exists := config.Provider[providerKey]
-	if !exists {
-		fmt.Printf("Provider '%s' not found\n", providerKey)
-		return nil
+	if _, exists := dest.Models[modelID]; exists {
+		if !promptBool(fmt.Sprintf("Warning: Model '%s' already exists in provider '%s'. Overwrite?", modelID, destKey), false) {
+			return errCancelled
+		}
 	}
 
-	model, exists := provider.Models[modelID]
-	if !exists {
-		fmt.Printf("Model '%s' not found\n", modelID)
-		return nil
+	source := config.Provider[sourceKey]
+	if dest.Models == nil {
+		dest.Models = make(map[string]Model)
+		config.Provider[destKey] = dest
 	}
+	dest.Models[modelID] = source.Models[modelID]
+	delete(source.Models, modelID)
 
-	if !promptBool(fmt.Sprintf("\nAre you sure you want to delete model '%s' from provider '%s'?", model.Name, provider.Name), false) {
-		fmt.Println("Cancelled")
-		return nil
+	destRef := fmt.Sprintf("%s/%s", destKey, modelID)
+	if config.Model == sourceRef {
+		config.Model = destRef
+		fmt.Printf("Default model updated to: %s\n", destRef)
 	}
-
-	delete(provider.Models, modelID)
-
-	if config.Model == selectedModel {
-		fmt.Printf("Warning: This was the default model. Default model cleared.\n")
-		config.Model = ""
+	if config.SmallModel == sourceRef {
+		config.SmallModel = destRef
+		fmt.Printf("Small model updated to: %s\n", destRef)
 	}
 
 	if err := saveConfig(config, configPath); err != nil {
 		return err
 	}
 
-	fmt.Printf("Deleted model: %s\n", model.Name)
+	fmt.Printf("Moved %s to %s\n", sourceRef, destRef)
 	return nil
 }
 
-func setDefaultModel() error {
+func renameModel(args []string) error {
+	selector, rest := splitModelSelector(args)
+	if len(rest) > 1 {
+		return fmt.Errorf("usage: rename-model [provider/model] [new-id]")
+	}
+
 	configPath, err := getConfigPath()
 	if err != nil {
 		return err
@@ -350,42 +2113,99 @@ func setDefaultModel() error {
 		return nil
 	}
 
-	fmt.Println("\n=== Set Default Model ===")
-	fmt.Println("Available models:")
+	var providerKey, oldID string
+	if selector == "" && len(rest) == 1 {
+		// Only a provider was given; pick the model from its list.
+		providerKey, err = selectProvider(config, rest[0])
+		if err != nil {
+			return err
+		}
+		rest = nil
 
-	models := []string{}
-	i := 1
-	for providerKey, provider := range config.Provider {
-		for modelID := range provider.Models {
-			modelRef := fmt.Sprintf("%s/%s", providerKey, modelID)
-			models = append(models, modelRef)
-			fmt.Printf("  %d. %s (%s)\n", i, modelRef, provider.Models[modelID].Name)
-			i++
+		provider := config.Provider[providerKey]
+		if len(provider.Models) == 0 {
+			fmt.Println("No models configured")
+			return nil
+		}
+		fmt.Printf("\nModels in %s:\n", providerKey)
+		if oldID = promptNewModelID(provider.Models); oldID == "" {
+			return errCancelled
 		}
+		if _, exists := provider.Models[oldID]; !exists {
+			return fmt.Errorf("model '%s' %w in provider '%s'", oldID, ocfg.ErrNotFound, providerKey)
+		}
+	} else {
+		ref, err := selectModel(config, selector, "\n=== Rename Model ===", "Available models:")
+		if err != nil {
+			return err
+		}
+		providerKey, oldID, _ = ocfg.ParseModelRef(ref)
 	}
+	provider := config.Provider[providerKey]
 
-	choice := getMenuChoice(len(models))
-	if choice == -1 {
-		fmt.Println("Invalid choice")
-		return nil
+	var newID string
+	if len(rest) == 1 {
+		newID = rest[0]
+	} else {
+		newID = promptString(fmt.Sprintf("New ID for '%s'", oldID), "")
+		if newID == "" {
+			return errCancelled
+		}
 	}
-	if choice == 0 {
-		fmt.Println("Cancelled")
+	if newID == oldID {
+		fmt.Println("New ID is the same as the old one; nothing to do")
 		return nil
 	}
 
-	selectedModel := models[choice-1]
-	config.Model = selectedModel
+	overwrite := false
+	if _, exists := provider.Models[newID]; exists {
+		if !promptBool(fmt.Sprintf("Warning: Model '%s' already exists in provider '%s'. Overwrite?", newID, providerKey), false) {
+			return errCancelled
+		}
+		overwrite = true
+	}
+
+	updated, err := config.RenameModel(providerKey, oldID, newID, overwrite)
+	if err != nil {
+		return err
+	}
+
+	newRef := ocfg.ModelRef(providerKey, newID)
+	for _, field := range updated {
+		if field == "model" {
+			fmt.Printf("Default model updated to: %s\n", newRef)
+		} else {
+			fmt.Printf("Small model updated to: %s\n", newRef)
+		}
+	}
 
 	if err := saveConfig(config, configPath); err != nil {
 		return err
 	}
 
-	fmt.Printf("Default model set to: %s\n", selectedModel)
+	fmt.Printf("Renamed %s to %s\n", ocfg.ModelRef(providerKey, oldID), newRef)
 	return nil
 }
 
-func addModel() error {
+func setModelLimit(args []string) error {
+	fs := flag.NewFlagSet("set-limit", flag.ContinueOnError)
+	contextFlag := fs.Int("context", 0, "context window limit in tokens (0 removes it)")
+	outputFlag := fs.Int("output", 0, "output limit in tokens (0 removes it)")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	selector, rest := splitModelSelector(positional)
+	if len(rest) != 0 {
+		return fmt.Errorf("usage: set-limit [provider/model] [--context <tokens>] [--output <tokens>]")
+	}
+	if *contextFlag < 0 || *outputFlag < 0 {
+		return fmt.Errorf("--context and --output must be positive")
+	}
+
+	setFlags := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+
 	configPath, err := getConfigPath()
 	if err != nil {
 		return err
@@ -396,90 +2216,47 @@ func addModel() error {
 		return err
 	}
 
-	if len(config.Provider) == 0 {
+	if selector == "" && len(config.Provider) == 0 {
 		fmt.Println("No providers configured. Use 'add' command first.")
 		return nil
 	}
-
-	fmt.Println("\n=== Add Model to Existing Provider ===")
-	fmt.Println("Available providers:")
-
-	providers := []string{}
-	i := 1
-	for key, provider := range config.Provider {
-		fmt.Printf("  %d. %s (%s) - %d model(s)\n", i, key, provider.Name, len(provider.Models))
-		providers = append(providers, key)
-		i++
+	ref, err := selectModel(config, selector, "\n=== Set Model Limits ===", "Available models:")
+	if err != nil {
+		return err
 	}
 
-	selection := promptString("Enter provider number or key", "")
-	var providerKey string
+	providerKey, modelID, _ := strings.Cut(ref, "/")
+	model := config.Provider[providerKey].Models[modelID]
 
-	if selection == "" {
-		fmt.Println("Cancelled")
-		return nil
+	var limit ModelLimit
+	if model.Limit != nil {
+		limit = *model.Limit
 	}
 
-	num := 0
-	if _, err := fmt.Sscanf(selection, "%d", &num); err == nil && num > 0 && num <= len(providers) {
-		providerKey = providers[num-1]
+	if len(setFlags) == 0 {
+		// Blank answers keep the current values.
+		limit.Context = promptInt("Context limit (tokens)", limit.Context)
+		limit.Output = promptInt("Output limit (tokens)", limit.Output)
 	} else {
-		providerKey = selection
-	}
-
-	if _, exists := config.Provider[providerKey]; !exists {
-		fmt.Printf("Provider '%s' not found\n", providerKey)
-		return nil
-	}
-
-	provider := config.Provider[providerKey]
-	fmt.Printf("\nAdding model to provider: %s (%s)\n", provider.Name, providerKey)
-
-	modelID := promptString("Model ID (e.g., qwen3-coder)", "")
-	if modelID == "" {
-		fmt.Println("Cancelled")
-		return nil
-	}
-
-	modelName := promptString("Display name", modelID)
-	model := Model{Name: modelName}
-
-	if promptBool("Configure token limits?", false) {
-		contextLimit := promptString("Context limit (tokens, e.g., 128000)", "")
-		outputLimit := promptString("Output limit (tokens, e.g., 65536)", "")
-
-		if contextLimit != "" || outputLimit != "" {
-			limit := &ModelLimit{}
-			if contextLimit != "" {
-				fmt.Sscanf(contextLimit, "%d", &limit.Context)
-			}
-			if outputLimit != "" {
-				fmt.Sscanf(outputLimit, "%d", &limit.Output)
-			}
-			model.Limit = limit
+		if setFlags["context"] {
+			limit.Context = *contextFlag
 		}
-	}
-
-	if _, exists := provider.Models[modelID]; exists {
-		if !promptBool(fmt.Sprintf("\nWarning: Model '%s' already exists. Overwrite?", modelID), false) {
-			fmt.Println("Cancelled")
-			return nil
+		if setFlags["output"] {
+			limit.Output = *outputFlag
 		}
 	}
 
-	provider.Models[modelID] = model
-
-	if promptBool("Set as default model?", false) {
-		config.Model = fmt.Sprintf("%s/%s", providerKey, modelID)
+	if limit.Context == 0 && limit.Output == 0 {
+		model.Limit = nil
+	} else {
+		model.Limit = &limit
 	}
+	config.Provider[providerKey].Models[modelID] = model
 
 	if err := saveConfig(config, configPath); err != nil {
 		return err
 	}
 
-	fmt.Printf("\nModel '%s' added to provider '%s'\n", modelName, provider.Name)
-	if config.Model == fmt.Sprintf("%s/%s", providerKey, modelID) {
-		fmt.Printf("Default model: %s\n", config.Model)
-	}
+	fmt.Printf("Limits for %s: context %s, output %s\n", ref, formatLimit(limit.Context), formatLimit(limit.Output))
 	return nil
 }
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	ocfg "github.com/liamwilliams93/opencode-config-wizard/pkg/config"
+)
+
+func TestPurgeDisabledProviders(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		providers    []string
+		disabled     []string
+		wantPurged   []string
+		wantDisabled []string
+		wantKept     []string
+	}{
+		{
+			name:         "built-in entries stay",
+			providers:    []string{"ollama", "lmstudio"},
+			disabled:     []string{"ollama", "openai", "anthropic"},
+			wantPurged:   []string{"ollama"},
+			wantDisabled: []string{"openai", "anthropic"},
+			wantKept:     []string{"lmstudio"},
+		},
+		{
+			name:         "nothing configured is disabled",
+			providers:    []string{"ollama"},
+			disabled:     []string{"openai"},
+			wantDisabled: []string{"openai"},
+			wantKept:     []string{"ollama"},
+		},
+		{
+			name:       "duplicate entry",
+			providers:  []string{"ollama"},
+			disabled:   []string{"ollama", "ollama"},
+			wantPurged: []string{"ollama"},
+		},
+	} {
+		config := ocfg.New()
+		for _, key := range tc.providers {
+			config.Provider[key] = Provider{Name: key}
+		}
+		config.DisabledProviders = tc.disabled
+
+		purged := disabledConfiguredProviders(config)
+		if !reflect.DeepEqual(purged, tc.wantPurged) {
+			t.Errorf("%s: purging %v, want %v", tc.name, purged, tc.wantPurged)
+		}
+		purgeProviders(config, purged)
+
+		if !reflect.DeepEqual(config.DisabledProviders, tc.wantDisabled) {
+			t.Errorf("%s: disabled_providers = %v, want %v", tc.name, config.DisabledProviders, tc.wantDisabled)
+		}
+		for _, key := range tc.wantPurged {
+			if _, exists := config.Provider[key]; exists {
+				t.Errorf("%s: provider %q still configured", tc.name, key)
+			}
+		}
+		for _, key := range tc.wantKept {
+			if _, exists := config.Provider[key]; !exists {
+				t.Errorf("%s: provider %q was deleted", tc.name, key)
+			}
+		}
+	}
+}
+
+func TestParseOptionValue(t *testing.T) {
+	for _, tc := range []struct {
+		key, value string
+		want       interface{}
+		wantErr    bool
+	}{
+		{"maxRetries", "3", 3, false},
+		{"temperature", "0.7", 0.7, false},
+		{"stream", "true", true, false},
+		{"organization", "acme", "acme", false},
+		{"apiKey", "12345", "12345", false},
+		{"baseURL", "true", "true", false},
+		{"temperature", "nan", nil, true},
+		{"temperature", "inf", nil, true},
+		{"temperature", "-Infinity", nil, true},
+		{"temperature", "1e999", nil, true},
+	} {
+		got, err := parseOptionValue(tc.key, tc.value)
+		if tc.wantErr {
+			if !errors.Is(err, ocfg.ErrInvalid) {
+				t.Errorf("parseOptionValue(%q, %q) error = %v, want ErrInvalid", tc.key, tc.value, err)
+			}
+			continue
+		}
+		if err != nil || got != tc.want {
+			t.Errorf("parseOptionValue(%q, %q) = %#v, %v, want %#v", tc.key, tc.value, got, err, tc.want)
+		}
+	}
+}
+
+func TestPromptTokenLimit(t *testing.T) {
+	for _, tc := range []struct {
+		input string
+		want  int
+	}{
+		{"\n", 0},
+		{"1\n", 8192},
+		{"4\n", 128000},
+		{"128k\n", 128000},
+		{"16K\n", 16384},
+		{"6\n5\n", 5},
+		{"custom\n65536\n", 65536},
+		{"65536\n2\n", 16384},
+		{"64k\n\n", 0},
+	} {
+		withInput(t, tc.input)
+		if got := promptTokenLimit("Context limit", contextPresets); got != tc.want {
+			t.Errorf("input %q: got %d, want %d", tc.input, got, tc.want)
+		}
+	}
+}
+
+func TestPromptNewModelID(t *testing.T) {
+	models := map[string]Model{"qwen3-coder": {}, "gpt-oss": {}, "llama3.1": {}}
+	for _, tc := range []struct {
+		input string
+		want  string
+	}{
+		{"\n", "gpt-oss"},
+		{"3\n", "qwen3-coder"},
+		{"llama3.1\n", "llama3.1"},
+		{"mistral\n", ""},
+	} {
+		withInput(t, tc.input)
+		if got := promptNewModelID(models); got != tc.want {
+			t.Errorf("input %q: got %q, want %q", tc.input, got, tc.want)
+		}
+	}
+}
+
+func TestRenameHeaderKeepsValues(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Chdir(t.TempDir())
+	withInput(t, "")
+	t.Cleanup(func() { configBackedUp = false })
+
+	path := filepath.Join(home, ".config", "opencode", "opencode.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	data := `{"provider": {"ollama": {"name": "Ollama", "options": {"headers": {"X-Retries": 3, "X-Debug": true, "X-Org": "acme"}}, "models": {"qwen3-coder": {"name": "Qwen3 Coder"}}}}}`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := renameHeader([]string{"ollama", "X-Retries", "X-Max-Retries"}); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := loadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"X-Max-Retries": float64(3), "X-Debug": true, "X-Org": "acme"}
+	if got := config.Provider["ollama"].Options["headers"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("headers = %#v, want %#v", got, want)
+	}
+}
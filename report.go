@@ -0,0 +1,247 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+)
+
+// redactURL removes credentials and query parameters, which often carry
+// tokens, from rawURL.
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	if u.User != nil {
+		u.User = url.User("REDACTED")
+	}
+	if u.RawQuery != "" {
+		u.RawQuery = "REDACTED"
+	}
+	return u.String()
+}
+
+// secretArgWords are the words that mark a command-line flag or variable as
+// holding a secret, e.g. --api-key or GITHUB_TOKEN=...
+var secretArgWords = []string{"key", "token", "secret", "password", "passwd", "auth", "credential", "bearer"}
+
+// secretValuePrefixes are prefixes of well-known token formats, which are
+// redacted wherever they appear.
+var secretValuePrefixes = []string{"sk-", "ghp_", "gho_", "ghs_", "github_pat_", "xoxb-", "xoxp-", "glpat-", "AKIA"}
+
+// isSecretArgName reports whether a flag or variable name suggests its value
+// is a secret.
+func isSecretArgName(name string) bool {
+	name = strings.ToLower(name)
+	for _, word := range secretArgWords {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactCommand joins an MCP server command for display, replacing anything
+// that looks like a secret with REDACTED: the values of --flag=value,
+// NAME=value and "Name: value" arguments whose names suggest a secret, the
+// argument after such a flag, well-known token formats, and credentials and
+// query strings in URLs.
+func redactCommand(command []string) string {
+	args := make([]string, len(command))
+	redactNext := false
+	for i, arg := range command {
+		switch {
+		case redactNext:
+			arg = "REDACTED"
+			redactNext = false
+		case strings.Contains(arg, "://"):
+			arg = redactURL(arg)
+		case strings.Contains(arg, "="):
+			name, _, _ := strings.Cut(arg, "=")
+			if isSecretArgName(name) {
+				arg = name + "=REDACTED"
+			}
+		case strings.Contains(arg, ":"):
+			name, _, _ := strings.Cut(arg, ":")
+			if isSecretArgName(name) {
+				arg = name + ": REDACTED"
+			}
+		case i > 0 && strings.HasPrefix(arg, "-"):
+			redactNext = isSecretArgName(arg)
+		}
+		for _, prefix := range secretValuePrefixes {
+			if strings.HasPrefix(arg, prefix) {
+				arg = "REDACTED"
+			}
+		}
+		args[i] = arg
+	}
+	return strings.Join(args, " ")
+}
+
+// markdownCell escapes text for use inside a Markdown table cell.
+func markdownCell(text string) string {
+	if text == "" {
+		return "-"
+	}
+	text = strings.ReplaceAll(text, "|", `\|`)
+	return strings.ReplaceAll(text, "\n", " ")
+}
+
+func formatLimit(tokens int) string {
+	if tokens == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d", tokens)
+}
+
+// writeReport renders config as a Markdown overview. Secret values are never
+// included; headers and environment variables are listed by name only.
+func writeReport(b *strings.Builder, config *Config) {
+	b.WriteString("# OpenCode Configuration\n\n")
+
+	if config.Model != "" {
+		fmt.Fprintf(b, "- **Default model**: `%s`\n", config.Model)
+	}
+	if config.SmallModel != "" {
+		fmt.Fprintf(b, "- **Small model**: `%s`\n", config.SmallModel)
+	}
+	if len(config.DisabledProviders) > 0 {
+		fmt.Fprintf(b, "- **Disabled providers**: %s\n", strings.Join(config.DisabledProviders, ", "))
+	}
+	if config.Model != "" || config.SmallModel != "" || len(config.DisabledProviders) > 0 {
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## Providers\n\n")
+	if len(config.Provider) == 0 {
+		b.WriteString("No providers configured.\n\n")
+	} else {
+		keys := config.ProviderKeys()
+
+		b.WriteString("| Provider | Name | Base URL | Models |\n")
+		b.WriteString("|----------|------|----------|--------|\n")
+		for _, key := range keys {
+			provider := config.Provider[key]
+			baseURL, _ := provider.Options["baseURL"].(string)
+			fmt.Fprintf(b, "| `%s` | %s | %s | %d |\n", key, markdownCell(provider.Name), markdownCell(redactURL(baseURL)), len(provider.Models))
+		}
+		b.WriteString("\n")
+
+		for _, key := range keys {
+			provider := config.Provider[key]
+			fmt.Fprintf(b, "### %s (`%s`)\n\n", markdownCell(provider.Name), key)
+
+			if headers := providerHeaders(provider); len(headers) > 0 {
+				names := make([]string, 0, len(headers))
+				for name := range headers {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				fmt.Fprintf(b, "Headers: %s\n\n", strings.Join(names, ", "))
+			}
+
+			if len(provider.Models) == 0 {
+				b.WriteString("No models configured.\n\n")
+				continue
+			}
+
+			ids := make([]string, 0, len(provider.Models))
+			for id := range provider.Models {
+				ids = append(ids, id)
+			}
+			sort.Strings(ids)
+
+			b.WriteString("| Model | Name | Context | Output |\n")
+			b.WriteString("|-------|------|---------|--------|\n")
+			for _, id := range ids {
+				model := provider.Models[id]
+				context, output := "-", "-"
+				if model.Limit != nil {
+					context, output = formatLimit(model.Limit.Context), formatLimit(model.Limit.Output)
+				}
+				fmt.Fprintf(b, "| `%s` | %s | %s | %s |\n", id, markdownCell(model.Name), context, output)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("## MCP Servers\n\n")
+	if len(config.MCP) == 0 {
+		b.WriteString("No MCP servers configured.\n")
+		return
+	}
+
+	names := make([]string, 0, len(config.MCP))
+	for name := range config.MCP {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	b.WriteString("| Server | Type | Command / URL | Enabled | Environment / Headers |\n")
+	b.WriteString("|--------|------|---------------|---------|-----------------------|\n")
+	for _, name := range names {
+		server := config.MCP[name]
+
+		target := redactURL(server.URL)
+		var secrets []string
+		if server.Type == "local" {
+			target = redactCommand(server.Command)
+			for key := range server.Environment {
+				secrets = append(secrets, key)
+			}
+		} else {
+			for key := range server.Headers {
+				secrets = append(secrets, key)
+			}
+		}
+		sort.Strings(secrets)
+
+		enabled := "yes"
+		if server.Enabled != nil && !*server.Enabled {
+			enabled = "no"
+		}
+
+		fmt.Fprintf(b, "| `%s` | %s | %s | %s | %s |\n", name, server.Type, markdownCell(target), enabled, markdownCell(strings.Join(secrets, ", ")))
+	}
+}
+
+func reportConfig(args []string) error {
+	fs := flag.NewFlagSet("report", flag.ContinueOnError)
+	out := fs.String("out", "", "file to write the report to (default: stdout)")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return fmt.Errorf("usage: report [--out <file>]")
+	}
+
+	configPath, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	writeReport(&b, config)
+
+	if *out == "" {
+		fmt.Print(b.String())
+		return nil
+	}
+
+	if err := os.WriteFile(*out, []byte(b.String()), 0644); err != nil {
+		return err
+	}
+	fmt.Printf("Report written to: %s\n", *out)
+	return nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	ocfg "github.com/liamwilliams93/opencode-config-wizard/pkg/config"
+)
+
+// resetConfirmation is the word the user has to type before reset wipes the
+// config; a y/n answer is too easy to give by accident.
+const resetConfirmation = "reset"
+
+func resetConfig(args []string) error {
+	fs := flag.NewFlagSet("reset", flag.ContinueOnError)
+	force := fs.Bool("force", false, "reset without asking for confirmation")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return fmt.Errorf("usage: reset [--force]")
+	}
+
+	configPath, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(configPath)
+	if os.IsNotExist(err) {
+		fmt.Println("No config file to reset")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if !*force {
+		fmt.Printf("This replaces %s with an empty config, removing every provider, model and MCP server.\n", configPath)
+		answer := promptString(fmt.Sprintf("Type '%s' to confirm", resetConfirmation), "")
+		if answer != resetConfirmation {
+			return errCancelled
+		}
+	}
+
+	// Written by hand rather than through saveConfig, since an empty mcp map
+	// would otherwise be left out.
+	indent, err := outputIndent()
+	if err != nil {
+		return err
+	}
+	empty := map[string]interface{}{
+		"$schema":  ocfg.SchemaURL,
+		"provider": map[string]interface{}{},
+		"mcp":      map[string]interface{}{},
+	}
+	var data []byte
+	if indent == "" {
+		data, err = json.Marshal(empty)
+	} else {
+		data, err = json.MarshalIndent(empty, "", indent)
+	}
+	if err != nil {
+		return err
+	}
+
+	current := readConfigQuietly(configPath)
+	backupPath, err := backupConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("backing up config: %w", err)
+	}
+
+	if err := os.WriteFile(configPath, append(data, '\n'), info.Mode().Perm()); err != nil {
+		return err
+	}
+	logConfigChange(configPath, current, ocfg.New())
+	recordSave(configPath, current, ocfg.New())
+
+	fmt.Printf("Backed up current config to: %s\n", backupPath)
+	fmt.Printf("Reset %s to an empty config\n", configPath)
+	fmt.Println("Run 'undo' to bring the previous config back")
+	return nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	ocfg "github.com/liamwilliams93/opencode-config-wizard/pkg/config"
+)
+
+// selectModel returns the model named by selector, a provider/model reference
+// given on the command line, after checking that it exists. If selector is
+// empty the user picks one from a menu instead, with heading printed above it;
+// leaving the menu blank returns errCancelled.
+func selectModel(config *Config, selector string, heading ...string) (string, error) {
+	if selector != "" {
+		if err := config.ValidateModelRef(selector); err != nil {
+			return "", err
+		}
+		return selector, nil
+	}
+
+	for _, line := range heading {
+		fmt.Println(line)
+	}
+	ref := promptModelRef(config)
+	if ref == "" {
+		return "", errCancelled
+	}
+	return ref, nil
+}
+
+// selectProvider is selectModel for providers: selector is a provider key, or
+// empty to pick one from a menu. A selector with a slash is rejected, since
+// it is a model reference rather than a provider key.
+func selectProvider(config *Config, selector string, heading ...string) (string, error) {
+	if strings.Contains(selector, "/") {
+		return "", fmt.Errorf("%w provider key '%s': provider keys can't contain '/'", ocfg.ErrInvalid, selector)
+	}
+
+	key := selector
+	if key == "" {
+		for _, line := range heading {
+			fmt.Println(line)
+		}
+		if key = promptProviderKey(config); key == "" {
+			return "", errCancelled
+		}
+	}
+
+	if _, exists := config.Provider[key]; !exists {
+		return "", fmt.Errorf("provider '%s' %w", key, ocfg.ErrNotFound)
+	}
+	return key, nil
+}
+
+// splitModelSelector takes a model selector off the front of args, written
+// either as provider/model or, as older versions expected, as separate
+// provider and model arguments. It returns the selector and the arguments
+// after it; with too few arguments the selector is empty and rest is args.
+// Provider keys can't contain a slash, so the two forms can't be confused,
+// though model IDs can (openrouter/anthropic/claude-sonnet-4).
+func splitModelSelector(args []string) (selector string, rest []string) {
+	switch {
+	case len(args) > 0 && strings.Contains(args[0], "/"):
+		return args[0], args[1:]
+	case len(args) > 1:
+		return ocfg.ModelRef(args[0], args[1]), args[2:]
+	default:
+		return "", args
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+)
+
+// configStats holds the numbers reported by the stats command.
+type configStats struct {
+	Providers        int     `json:"providers"`
+	Models           int     `json:"models"`
+	ModelsWithLimits int     `json:"models_with_limits"`
+	ContextMin       int     `json:"context_min"`
+	ContextMax       int     `json:"context_max"`
+	ContextAvg       float64 `json:"context_avg"`
+	MCPLocal         int     `json:"mcp_local"`
+	MCPRemote        int     `json:"mcp_remote"`
+	MCPEnabled       int     `json:"mcp_enabled"`
+}
+
+// computeStats summarizes config. Context window figures only cover models
+// with a context limit set.
+func computeStats(config *Config) configStats {
+	var stats configStats
+	stats.Providers = len(config.Provider)
+
+	contextTotal, contextCount := 0, 0
+	for _, provider := range config.Provider {
+		stats.Models += len(provider.Models)
+		for _, model := range provider.Models {
+			if model.Limit == nil || (model.Limit.Context == 0 && model.Limit.Output == 0) {
+				continue
+			}
+			stats.ModelsWithLimits++
+
+			context := model.Limit.Context
+			if context == 0 {
+				continue
+			}
+			if contextCount == 0 || context < stats.ContextMin {
+				stats.ContextMin = context
+			}
+			if context > stats.ContextMax {
+				stats.ContextMax = context
+			}
+			contextTotal += context
+			contextCount++
+		}
+	}
+	if contextCount > 0 {
+		stats.ContextAvg = float64(contextTotal) / float64(contextCount)
+	}
+
+	for _, server := range config.MCP {
+		if server.Type == "local" {
+			stats.MCPLocal++
+		} else {
+			stats.MCPRemote++
+		}
+		if server.Enabled == nil || *server.Enabled {
+			stats.MCPEnabled++
+		}
+	}
+
+	return stats
+}
+
+func showStats(args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the numbers as a JSON object")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return fmt.Errorf("usage: stats [--json]")
+	}
+
+	configPath, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	stats := computeStats(config)
+
+	if *asJSON {
+		data, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Println("\n=== Config Stats ===")
+	fmt.Printf("Providers:          %d\n", stats.Providers)
+	fmt.Printf("Models:             %d\n", stats.Models)
+	fmt.Printf("Models with limits: %d\n", stats.ModelsWithLimits)
+	if stats.ContextMax > 0 {
+		fmt.Printf("Context window:     min %d, max %d, avg %.0f\n", stats.ContextMin, stats.ContextMax, stats.ContextAvg)
+	}
+	fmt.Printf("MCP servers:        %d local, %d remote (%d enabled)\n", stats.MCPLocal, stats.MCPRemote, stats.MCPEnabled)
+	return nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// useTUI makes list selections use an arrow-key menu when stdin is a
+// terminal; set by the --tui flag.
+var useTUI bool
+
+// tuiSelect lets the user pick one of keys, shown as labels, with the arrow
+// keys (or j/k) and Enter. It returns the chosen key, or "" if the user
+// cancelled with q, Esc or Ctrl-C. ok is false if the menu can't be used, in
+// which case the caller falls back to its numbered prompt: without --tui or
+// when stdin isn't a terminal.
+func tuiSelect(title string, keys, labels []string) (key string, ok bool) {
+	stdin := int(os.Stdin.Fd())
+	if !useTUI || nonInteractive || len(keys) == 0 || !term.IsTerminal(stdin) {
+		return "", false
+	}
+
+	state, err := term.MakeRaw(stdin)
+	if err != nil {
+		return "", false
+	}
+	defer term.Restore(stdin, state)
+
+	fmt.Print("\033[?25l")
+	defer fmt.Print("\033[?25h")
+
+	// Raw mode turns off the terminal's newline translation, so lines
+	// end in \r\n.
+	fmt.Printf("%s %s\r\n", title, dim("(arrows to move, Enter to select, q to cancel)"))
+	selected := 0
+	render := func() {
+		for i, label := range labels {
+			if i == selected {
+				fmt.Printf("\r\033[K%s %s\r\n", green(">"), label)
+			} else {
+				fmt.Printf("\r\033[K  %s\r\n", label)
+			}
+		}
+	}
+	render()
+
+	buf := make([]byte, 3)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return "", true
+		}
+		switch input := string(buf[:n]); input {
+		case "\033[A", "k":
+			if selected > 0 {
+				selected--
+			}
+		case "\033[B", "j":
+			if selected < len(keys)-1 {
+				selected++
+			}
+		case "\r", "\n":
+			return keys[selected], true
+		case "q", "\033", "\x03":
+			return "", true
+		default:
+			continue
+		}
+		fmt.Printf("\033[%dA", len(labels))
+		render()
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// describeBackup turns a backup file name back into the command and time it
+// was taken at, e.g. "before delete at 12:03".
+func describeBackup(configPath, backupPath string) string {
+	base := strings.TrimSuffix(filepath.Base(configPath), filepath.Ext(configPath))
+	name := strings.TrimSuffix(filepath.Base(backupPath), ".json")
+	name = strings.TrimPrefix(name, base+"-")
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		name = name[:i]
+	}
+
+	if len(name) < len(backupTimeFormat) {
+		return filepath.Base(backupPath)
+	}
+	stamp, label := name[:len(backupTimeFormat)], strings.TrimPrefix(name[len(backupTimeFormat):], "-")
+
+	taken, err := time.ParseInLocation(backupTimeFormat, stamp, time.Local)
+	if err != nil {
+		return filepath.Base(backupPath)
+	}
+
+	when := taken.Format("15:04")
+	if taken.YearDay() != time.Now().YearDay() || taken.Year() != time.Now().Year() {
+		when = taken.Format("2006-01-02 15:04")
+	}
+
+	if label == "" {
+		return "from " + when
+	}
+	return fmt.Sprintf("from before %s at %s", label, when)
+}
+
+func undoLastChange(args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("usage: undo")
+	}
+
+	configPath, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	backups, err := listBackups(configPath)
+	if err != nil {
+		return err
+	}
+	if len(backups) == 0 {
+		fmt.Println("Nothing to undo")
+		return nil
+	}
+	latest := backups[0]
+
+	data, err := os.ReadFile(latest)
+	if err != nil {
+		return err
+	}
+	restored, err := loadConfigData(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", latest, err)
+	}
+	current := readConfigQuietly(configPath)
+
+	// Back up the current state first so the undo can itself be undone.
+	if _, err := os.Stat(configPath); err == nil {
+		if _, err := backupConfig(configPath); err != nil {
+			return err
+		}
+	}
+
+	perm := os.FileMode(0600)
+	if info, err := os.Stat(configPath); err == nil {
+		perm = info.Mode().Perm()
+	}
+	if err := os.WriteFile(configPath, data, perm); err != nil {
+		return err
+	}
+	logConfigChange(configPath, current, restored)
+	recordSave(configPath, current, restored)
+
+	fmt.Printf("Restored config %s\n", describeBackup(configPath, latest))
+	fmt.Println("Run 'undo' again to revert this")
+	return nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUndoRecordsSave(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Chdir(t.TempDir())
+	withInput(t, "")
+	t.Cleanup(func() {
+		configBackedUp, lastBackupPath = false, ""
+		savedConfigs = commandResult{}
+	})
+
+	path := filepath.Join(home, ".config", "opencode", "opencode.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"provider": {"ollama": {"name": "Ollama"}}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := backupConfig(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"provider": {}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	savedConfigs = commandResult{}
+	if err := undoLastChange(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(savedConfigs.Saved) != 1 || savedConfigs.Saved[0] != path {
+		t.Errorf("saved = %v, want [%s]", savedConfigs.Saved, path)
+	}
+	if len(savedConfigs.Changes) != 1 || savedConfigs.Changes[0] != "provider added: ollama" {
+		t.Errorf("changes = %q, want [\"provider added: ollama\"]", savedConfigs.Changes)
+	}
+}
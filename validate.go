@@ -0,0 +1,303 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+
+	ocfg "github.com/liamwilliams93/opencode-config-wizard/pkg/config"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// schemaURL is where opencode publishes its config schema, and the URL the
+// schema is registered under when compiling it.
+const schemaURL = ocfg.SchemaURL
+
+// embeddedSchema is a bundled copy of the config schema covering the sections
+// the wizard manages, so validate works offline.
+//
+//go:embed schema/config.schema.json
+var embeddedSchema []byte
+
+// getCacheDir returns the directory downloaded data is cached in, next to the
+// global config.
+func getCacheDir() (string, error) {
+	configPath, err := getGlobalConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), "cache"), nil
+}
+
+func getCachedSchemaPath() (string, error) {
+	cacheDir, err := getCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "config.schema.json"), nil
+}
+
+// refreshSchema downloads the published schema into the cache.
+func refreshSchema() ([]byte, error) {
+	client, err := newHTTPClient(defaultTestTimeout)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Get(schemaURL)
+	if err != nil {
+		return nil, fmt.Errorf("fetching schema: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching schema: server responded with %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("fetching schema: %w", err)
+	}
+	if !json.Valid(data) {
+		return nil, fmt.Errorf("fetching schema: response is not JSON")
+	}
+
+	cachePath, err := getCachedSchemaPath()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0700); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(cachePath, data, 0600); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// loadSchema returns the schema to validate against: a freshly downloaded one
+// if refresh is set, otherwise a previously cached one, falling back to the
+// embedded copy. It also describes where the schema came from.
+func loadSchema(refresh bool) (*jsonschema.Schema, string, error) {
+	data, source := embeddedSchema, "bundled schema"
+
+	if refresh {
+		fetched, err := refreshSchema()
+		if err != nil {
+			return nil, "", err
+		}
+		data, source = fetched, "schema downloaded from "+schemaURL
+	} else if cachePath, err := getCachedSchemaPath(); err == nil {
+		if cached, err := os.ReadFile(cachePath); err == nil {
+			data, source = cached, "cached schema from "+cachePath
+		}
+	}
+
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(schemaURL, bytes.NewReader(data)); err != nil {
+		return nil, "", fmt.Errorf("loading %s: %w", source, err)
+	}
+	schema, err := compiler.Compile(schemaURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("compiling %s: %w", source, err)
+	}
+	return schema, source, nil
+}
+
+// schemaViolation is a single problem found by schema validation.
+type schemaViolation struct {
+	Location string
+	Message  string
+}
+
+// collectViolations flattens a validation error into its most specific
+// causes.
+func collectViolations(err *jsonschema.ValidationError) []schemaViolation {
+	if len(err.Causes) == 0 {
+		location := err.InstanceLocation
+		if location == "" {
+			location = "/"
+		}
+		return []schemaViolation{{location, err.Message}}
+	}
+
+	var violations []schemaViolation
+	for _, cause := range err.Causes {
+		violations = append(violations, collectViolations(cause)...)
+	}
+	return violations
+}
+
+// validateConfigData checks raw config JSON against schema.
+func validateConfigData(schema *jsonschema.Schema, data []byte) ([]schemaViolation, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	data, _ = ocfg.StripTrailingCommas(data)
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		if syntaxErr, ok := err.(*json.SyntaxError); ok {
+			return nil, ocfg.NewParseError(data, syntaxErr.Offset, err)
+		}
+		return nil, err
+	}
+
+	err := schema.Validate(doc)
+	if err == nil {
+		return nil, nil
+	}
+	validationErr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return nil, err
+	}
+
+	violations := collectViolations(validationErr)
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].Location < violations[j].Location
+	})
+	return violations, nil
+}
+
+// schemaField returns the $schema value in raw config JSON and whether the
+// field is present at all.
+func schemaField(data []byte) (string, bool) {
+	data, _ = ocfg.StripTrailingCommas(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	var doc struct {
+		Schema *string `json:"$schema"`
+	}
+	if json.Unmarshal(data, &doc) != nil || doc.Schema == nil {
+		return "", false
+	}
+	return *doc.Schema, true
+}
+
+// checkSchemaField flags a missing or wrong $schema, which stops editors and
+// opencode validating the file, and offers to set it.
+func checkSchemaField(configPath string, data []byte) error {
+	value, present := schemaField(data)
+	if present && value == ocfg.SchemaURL {
+		return nil
+	}
+
+	if present {
+		fmt.Printf("  %s $schema is '%s', expected '%s'\n", red("x"), value, ocfg.SchemaURL)
+	} else {
+		fmt.Printf("  %s $schema is missing, expected '%s'\n", red("x"), ocfg.SchemaURL)
+	}
+	if !promptBool("Set $schema to "+ocfg.SchemaURL+"?", false) {
+		return nil
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+	config.Schema = ocfg.SchemaURL
+	if err := saveConfig(config, configPath); err != nil {
+		return err
+	}
+	fmt.Println("  " + green("Fixed $schema"))
+	return nil
+}
+
+// checkConfig is validate --check: it prints schema violations and the
+// problems doctor would report, other than warnings, and fails if there are
+// any. It never prompts or writes.
+func checkConfig(configPath string, data []byte, violations []schemaViolation) error {
+	var findings []string
+	for _, v := range violations {
+		findings = append(findings, fmt.Sprintf("%s: %s", v.Location, v.Message))
+	}
+
+	if config, _, err := ocfg.Parse(data); err == nil {
+		for _, issue := range diagnoseConfig(config, configPath) {
+			if !issue.warning {
+				findings = append(findings, issue.message)
+			}
+		}
+	}
+
+	if len(findings) == 0 {
+		return nil
+	}
+	for _, finding := range findings {
+		fmt.Printf("%s: %s\n", configPath, finding)
+	}
+	return fmt.Errorf("config is %w: %d problem(s)", ocfg.ErrInvalid, len(findings))
+}
+
+func validateConfig(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	refresh := fs.Bool("refresh-schema", false, "download the latest schema before validating")
+	check := fs.Bool("check", false, "print only problems and exit non-zero if there are any, without prompting")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return fmt.Errorf("usage: validate [--refresh-schema] [--check]")
+	}
+
+	configPath, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			if *check {
+				return fmt.Errorf("no config file at %s: %w", configPath, ocfg.ErrNotFound)
+			}
+			fmt.Println("No config file to validate")
+			return nil
+		}
+		return err
+	}
+	if data, err = ocfg.Decompress(data); err != nil {
+		return err
+	}
+
+	schema, source, err := loadSchema(*refresh)
+	if err != nil {
+		return err
+	}
+
+	violations, err := validateConfigData(schema, data)
+	if err != nil {
+		if parseErr, ok := err.(*ocfg.ParseError); ok {
+			parseErr.Path = configPath
+		}
+		return err
+	}
+
+	if *check {
+		return checkConfig(configPath, data, violations)
+	}
+
+	fmt.Printf("Validated %s against the %s\n", configPath, source)
+	if err := checkSchemaField(configPath, data); err != nil {
+		return err
+	}
+	if config, _, err := ocfg.Parse(data); err == nil {
+		for _, key := range config.EmptyProviders() {
+			fmt.Printf("  %s provider '%s' has no models\n", yellow("!"), key)
+		}
+	}
+	if len(violations) == 0 {
+		fmt.Println(green("Config is valid"))
+		return nil
+	}
+
+	for _, v := range violations {
+		fmt.Printf("  %s %s: %s\n", red("x"), v.Location, v.Message)
+	}
+	return fmt.Errorf("config is %w: %d schema problem(s)", ocfg.ErrInvalid, len(violations))
+}
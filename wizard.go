@@ -0,0 +1,153 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// providerPreset pre-fills the connection details of a well-known
+// OpenAI-compatible server in the guided setup.
+type providerPreset struct {
+	key     string
+	name    string
+	baseURL string
+	npm     string
+}
+
+var providerPresets = []providerPreset{
+	{key: "ollama", name: "Ollama", baseURL: "http://localhost:11434/v1", npm: defaultNPMPackage},
+	{key: "lmstudio", name: "LM Studio", baseURL: "http://localhost:1234/v1", npm: defaultNPMPackage},
+	{key: "llamacpp", name: "llama.cpp", baseURL: "http://localhost:8080/v1", npm: defaultNPMPackage},
+	{key: "vllm", name: "vLLM", baseURL: "http://localhost:8000/v1", npm: defaultNPMPackage},
+	{key: "openrouter", name: "OpenRouter", baseURL: "https://openrouter.ai/api/v1", npm: defaultNPMPackage},
+}
+
+// promptProviderPreset asks which preset to start from. The last option is a
+// custom provider, for which the SDK package is asked for too.
+func promptProviderPreset() providerPreset {
+	fmt.Println("Provider:")
+	for i, preset := range providerPresets {
+		fmt.Printf("  %d. %s (%s)\n", i+1, preset.name, preset.baseURL)
+	}
+	fmt.Printf("  %d. Custom\n", len(providerPresets)+1)
+
+	choice := promptInt("Select a provider", 1)
+	if choice >= 1 && choice <= len(providerPresets) {
+		return providerPresets[choice-1]
+	}
+	return providerPreset{key: "custom", name: "Custom Provider", baseURL: "http://localhost:11434/v1"}
+}
+
+// runWizard walks through setting up a provider, its models, the default and
+// small model and optionally an MCP server, saving the config at the end.
+func runWizard(args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("usage: wizard")
+	}
+
+	configPath, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(configPath), 0700); err != nil {
+		return err
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("\n=== Guided Setup ===")
+	fmt.Printf("This sets up a provider, its models and your default model in %s\n\n", configPath)
+
+	preset := promptProviderPreset()
+
+	providerKey := promptString("Provider key", preset.key)
+	if existing, exists := config.Provider[providerKey]; exists {
+		fmt.Printf("Warning: provider '%s' already exists with %d model(s), which will be lost\n", providerKey, len(existing.Models))
+		if !promptBool("Overwrite it?", false) {
+			return errCancelled
+		}
+	}
+
+	provider := promptProviderDetails(preset.npm, preset.name, preset.baseURL)
+
+	fmt.Println("\n=== Test Connection ===")
+	result := testProviderConnection(providerKey, provider, testOptions{})
+	if result.Err != nil {
+		fmt.Printf("%s Could not reach %s: %v\n", red("x"), provider.Options["baseURL"], result.Err)
+		if !promptBool("Continue anyway?", false) {
+			return errCancelled
+		}
+	} else {
+		fmt.Printf("%s %d model(s) available, responded in %s\n", green("OK"), result.Models, result.Elapsed)
+	}
+
+	fmt.Println("\n=== Add Models ===")
+	if result.Err == nil && result.Models > 0 {
+		ids, _, err := cachedProviderModels(providerKey, provider, true)
+		if err != nil {
+			return err
+		}
+		for i, id := range ids {
+			fmt.Printf("  %d. %s\n", i+1, id)
+		}
+		selection := promptString("Models to add (numbers or IDs, comma-separated, blank to enter manually)", "")
+		if selection != "" {
+			addSelectedModels(provider.Models, ids, selection)
+		}
+	}
+	if len(provider.Models) == 0 {
+		promptModels(provider.Models)
+	}
+
+	if err := config.AddProvider(providerKey, provider, true); err != nil {
+		return err
+	}
+
+	if len(provider.Models) > 0 {
+		fmt.Println("\n=== Default Model ===")
+		if modelID := promptNewModelID(provider.Models); modelID != "" {
+			config.Model = fmt.Sprintf("%s/%s", providerKey, modelID)
+		}
+		if len(provider.Models) > 1 && promptBool("Set a small model for lightweight tasks?", false) {
+			if modelID := promptNewModelID(provider.Models); modelID != "" {
+				config.SmallModel = fmt.Sprintf("%s/%s", providerKey, modelID)
+			}
+		}
+	}
+
+	printSetupSummary(config, providerKey, provider)
+	if !confirmSave() {
+		return errCancelled
+	}
+
+	if err := saveConfig(config, configPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nConfiguration saved to: %s\n", configPath)
+	fmt.Printf("Added provider: %s with %d model(s)\n", provider.Name, len(provider.Models))
+	if config.Model != "" {
+		fmt.Printf("Default model: %s\n", config.Model)
+	}
+	if config.SmallModel != "" {
+		fmt.Printf("Small model: %s\n", config.SmallModel)
+	}
+
+	if promptBool("\nAdd an MCP server now?", false) {
+		// The provider is already saved, so skipping the server still
+		// completes the setup.
+		if err := addMCPServer(nil); err != nil && !errors.Is(err, errCancelled) {
+			return err
+		}
+		return nil
+	}
+
+	fmt.Println("\nSetup complete. Run 'list' to review the config.")
+	return nil
+}